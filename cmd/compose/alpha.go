@@ -33,6 +33,15 @@ func alphaCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	cmd.AddCommand(
 		vizCommand(p, dockerCli, backend),
 		publishCommand(p, dockerCli, backend),
+		serveCommand(p, dockerCli, backend),
+		bakeCommand(p, dockerCli, backend),
+		lockCommand(p, dockerCli),
+		outdatedCommand(p, dockerCli, backend),
+		bundleCommand(p, dockerCli, backend),
+		fetchCommand(dockerCli),
+		cacheCommand(dockerCli),
+		lintCommand(p, dockerCli, backend),
+		exportCommand(p, dockerCli, backend),
 	)
 	return cmd
 }
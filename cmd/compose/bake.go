@@ -0,0 +1,89 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+type bakeOptions struct {
+	buildOptions
+	format string
+	output string
+}
+
+func bakeCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := bakeOptions{
+		buildOptions: buildOptions{ProjectOptions: p},
+	}
+	cmd := &cobra.Command{
+		Use:   "bake [OPTIONS] [SERVICE...]",
+		Short: "EXPERIMENTAL - Generate a buildx bake definition equivalent to compose build",
+		RunE: AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("ssh") && opts.ssh == "" {
+				opts.ssh = "default"
+			}
+			return runBake(ctx, dockerCli, backend, opts, args)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.format, "format", api.BakeFormatHCL, "Format of the bake definition (hcl, json)")
+	flags.StringVarP(&opts.output, "output", "o", "", "Save to file (default to stdout)")
+	flags.BoolVar(&opts.push, "push", false, "Push service images")
+	flags.StringArrayVar(&opts.args, "build-arg", []string{}, "Set build-time variables for services")
+	flags.StringVar(&opts.ssh, "ssh", "", "Set SSH authentications used when building service images. (use 'default' for using your default SSH Agent)")
+	flags.BoolVar(&opts.deps, "with-dependencies", false, "Also include dependencies (transitively)")
+	opts.addCacheFlags(flags)
+	opts.attestationOptions.addFlags(flags)
+	return cmd
+}
+
+func runBake(ctx context.Context, dockerCli command.Cli, backend api.Service, opts bakeOptions, services []string) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, services, cli.WithResolvedPaths(true), cli.WithoutEnvironmentResolution)
+	if err != nil {
+		return err
+	}
+	if err := applyPlatforms(project, false); err != nil {
+		return err
+	}
+
+	buildOpts, err := opts.toAPIBuildOptions(services)
+	if err != nil {
+		return err
+	}
+	definition, err := backend.Bake(ctx, project, api.BakeOptions{
+		Build:  buildOpts,
+		Format: opts.format,
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.output != "" {
+		return os.WriteFile(opts.output, []byte(definition), 0o666)
+	}
+	_, err = fmt.Fprint(dockerCli.Out(), definition)
+	return err
+}
@@ -19,16 +19,24 @@ package compose
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/util/buildflags"
 	"github.com/docker/cli/cli/command"
 	cliopts "github.com/docker/cli/opts"
+	"github.com/docker/compose/v2/pkg/compose"
 	ui "github.com/docker/compose/v2/pkg/progress"
+	"github.com/docker/compose/v2/pkg/utils"
+	"github.com/docker/go-units"
 	buildkit "github.com/moby/buildkit/util/progress/progressui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/docker/compose/v2/pkg/api"
 )
@@ -44,6 +52,71 @@ type buildOptions struct {
 	ssh     string
 	builder string
 	deps    bool
+	force   bool
+
+	analyzeContext bool
+
+	cacheFrom    []string
+	cacheTo      []string
+	cacheReplace bool
+
+	attestationOptions
+}
+
+type attestationOptions struct {
+	attests    []string
+	sbom       string
+	provenance string
+}
+
+func (opts *attestationOptions) addFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&opts.attests, "attest", []string{}, `Attestation parameters (format: "type=sbom,generator=image")`)
+	flags.StringVar(&opts.sbom, "sbom", "", `Shorthand for "--attest=type=sbom"`)
+	flags.StringVar(&opts.provenance, "provenance", "", `Shorthand for "--attest=type=provenance"`)
+}
+
+func (opts attestationOptions) attestations() []string {
+	var attests []string
+	attests = append(attests, opts.attests...)
+	if opts.provenance != "" {
+		attests = append(attests, buildflags.CanonicalizeAttest("provenance", opts.provenance))
+	}
+	if opts.sbom != "" {
+		attests = append(attests, buildflags.CanonicalizeAttest("sbom", opts.sbom))
+	}
+	return attests
+}
+
+// addCacheFlags registers flags to override services build cache import and export
+func (opts *buildOptions) addCacheFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&opts.cacheFrom, "cache-from", []string{}, "Add external cache sources (e.g. \"type=registry,ref=user/app:cache\"). Use \"service=NAME\" attribute to target a single service")
+	flags.StringArrayVar(&opts.cacheTo, "cache-to", []string{}, "Add cache export destinations (e.g. \"type=local,dest=path/to/dir\"). Use \"service=NAME\" attribute to target a single service")
+	flags.BoolVar(&opts.cacheReplace, "cache-replace", false, "Replace cache_from and cache_to set by the Compose file with the ones set by --cache-from and --cache-to, for the services they apply to")
+}
+
+// cacheOptions resolves build cache overrides from command line flags, or environment when not set
+func (opts buildOptions) cacheOptions() ([]string, []string, bool) {
+	cacheFrom, cacheTo, replace := opts.cacheFrom, opts.cacheTo, opts.cacheReplace
+	if len(cacheFrom) == 0 {
+		cacheFrom = splitCacheEntries(os.Getenv(ComposeBuildCacheFrom))
+	}
+	if len(cacheTo) == 0 {
+		cacheTo = splitCacheEntries(os.Getenv(ComposeBuildCacheTo))
+	}
+	if !replace {
+		replace = utils.StringToBool(os.Getenv(ComposeBuildCacheReplace))
+	}
+	return cacheFrom, cacheTo, replace
+}
+
+func splitCacheEntries(s string) []string {
+	var entries []string
+	for _, e := range strings.Split(s, ";") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries
 }
 
 func (opts buildOptions) toAPIBuildOptions(services []string) (api.BuildOptions, error) {
@@ -66,6 +139,11 @@ func (opts buildOptions) toAPIBuildOptions(services []string) (api.BuildOptions,
 	if builderName == "" {
 		builderName = os.Getenv("BUILDX_BUILDER")
 	}
+	cacheFrom, cacheTo, cacheReplace := opts.cacheOptions()
+	contextSizeWarning, err := buildContextSizeWarning()
+	if err != nil {
+		return api.BuildOptions{}, err
+	}
 
 	return api.BuildOptions{
 		Pull:     opts.pull,
@@ -78,9 +156,32 @@ func (opts buildOptions) toAPIBuildOptions(services []string) (api.BuildOptions,
 		Deps:     opts.deps,
 		SSHs:     SSHKeys,
 		Builder:  builderName,
+
+		CacheFrom:    cacheFrom,
+		CacheTo:      cacheTo,
+		CacheReplace: cacheReplace,
+		Force:        opts.force,
+		Attestations: opts.attestations(),
+
+		ContextSizeWarning: contextSizeWarning,
 	}, nil
 }
 
+// defaultBuildContextSizeWarning is the build context size above which a warning is emitted, unless set by COMPOSE_BUILD_CONTEXT_SIZE_WARNING
+const defaultBuildContextSizeWarning = "500MB"
+
+func buildContextSizeWarning() (int64, error) {
+	threshold, ok := os.LookupEnv(ComposeBuildContextSizeWarning)
+	if !ok {
+		threshold = defaultBuildContextSizeWarning
+	}
+	size, err := units.FromHumanSize(threshold)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", ComposeBuildContextSizeWarning, err)
+	}
+	return size, nil
+}
+
 func buildCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
 	opts := buildOptions{
 		ProjectOptions: p,
@@ -118,6 +219,10 @@ func buildCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.StringVar(&opts.ssh, "ssh", "", "Set SSH authentications used when building service images. (use 'default' for using your default SSH Agent)")
 	flags.StringVar(&opts.builder, "builder", "", "Set builder to use")
 	flags.BoolVar(&opts.deps, "with-dependencies", false, "Also build dependencies (transitively)")
+	flags.BoolVar(&opts.force, "force-build", false, "Build images even when build context and configuration have not changed")
+	flags.BoolVar(&opts.analyzeContext, "analyze-context", false, "Report build contexts content instead of building images")
+	opts.addCacheFlags(flags)
+	opts.attestationOptions.addFlags(flags)
 
 	flags.Bool("parallel", true, "Build images in parallel. DEPRECATED")
 	flags.MarkHidden("parallel") //nolint:errcheck
@@ -145,6 +250,10 @@ func runBuild(ctx context.Context, dockerCli command.Cli, backend api.Service, o
 		return err
 	}
 
+	if opts.analyzeContext {
+		return runAnalyzeContext(dockerCli, project)
+	}
+
 	apiBuildOptions, err := opts.toAPIBuildOptions(services)
 	if err != nil {
 		return err
@@ -153,3 +262,44 @@ func runBuild(ctx context.Context, dockerCli command.Cli, backend api.Service, o
 	apiBuildOptions.Memory = int64(opts.memory)
 	return backend.Build(ctx, project, apiBuildOptions)
 }
+
+// analyzedContextTop is the number of largest files and directories reported by --analyze-context
+const analyzedContextTop = 5
+
+func runAnalyzeContext(dockerCli command.Cli, project *types.Project) error {
+	out := dockerCli.Out()
+	names := project.ServiceNames()
+	sort.Strings(names)
+	for _, name := range names {
+		service := project.Services[name]
+		if service.Build == nil {
+			continue
+		}
+		summary, err := compose.AnalyzeBuildContext(service, analyzedContextTop)
+		if err != nil {
+			return err
+		}
+		if summary.Context == "" {
+			fmt.Fprintf(out, "%s: remote build context %s\n\n", name, service.Build.Context)
+			continue
+		}
+		fmt.Fprintf(out, "%s: %s (%s, %d files)\n", name, summary.Context, units.HumanSize(float64(summary.Size)), summary.Files)
+		printContextEntries(out, "Largest files", summary.LargestFiles)
+		printContextEntries(out, "Largest directories", summary.LargestDirs)
+		printContextEntries(out, "Commonly ignored paths, consider adding to .dockerignore", summary.Excludable)
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func printContextEntries(out io.Writer, title string, entries []api.BuildContextEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "  %s:\n", title)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		fmt.Fprintf(w, "    %s\t%s\n", units.HumanSize(float64(e.Size)), e.Path)
+	}
+	_ = w.Flush()
+}
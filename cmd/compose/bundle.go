@@ -0,0 +1,203 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type bundleSaveOptions struct {
+	*ProjectOptions
+	output   string
+	platform string
+}
+
+type bundleLoadOptions struct {
+	directory string
+	noUp      bool
+}
+
+func bundleCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "EXPERIMENTAL - Save a project with its images into an archive for offline use",
+	}
+	cmd.AddCommand(
+		bundleSaveCommand(p, dockerCli, backend),
+		bundleLoadCommand(dockerCli, backend),
+	)
+	return cmd
+}
+
+func bundleSaveCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := bundleSaveOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "save [OPTIONS] [SERVICE...]",
+		Short: "Save project files and service images into a bundle archive",
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runBundleSave(ctx, dockerCli, backend, opts, args)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&opts.output, "output", "o", "", "Bundle archive to write")
+	flags.StringVar(&opts.platform, "platform", "", "Platform to select service images for, if not set by service")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func runBundleSave(ctx context.Context, dockerCli command.Cli, backend api.Service, opts bundleSaveOptions, services []string) error {
+	collector := newModelFilesCollector(opts.remoteLoaders(dockerCli))
+	project, _, err := opts.ToProject(ctx, dockerCli, services, func(o *cli.ProjectOptions) error {
+		o.WithListeners(collector.listen)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	envFiles := opts.EnvFiles
+	if len(envFiles) == 0 {
+		// default .env file is used for interpolation when none is set explicitly
+		dotEnv := filepath.Join(project.WorkingDir, ".env")
+		if _, err := os.Stat(dotEnv); err == nil {
+			envFiles = []string{dotEnv}
+		}
+	}
+
+	return backend.Bundle(ctx, project, api.BundleOptions{
+		Output:     opts.output,
+		Platform:   opts.platform,
+		EnvFiles:   envFiles,
+		ModelFiles: collector.files(project),
+	})
+}
+
+func bundleLoadCommand(dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := bundleLoadOptions{}
+	cmd := &cobra.Command{
+		Use:   "load [OPTIONS] BUNDLE",
+		Short: "Load images from a bundle archive and run the bundled project",
+		Args:  cobra.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runBundleLoad(ctx, dockerCli, backend, opts, args[0])
+		}),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.directory, "directory", "", "Directory to extract project files into (default to project name in current directory)")
+	flags.BoolVar(&opts.noUp, "no-up", false, "Only load images and extract project files")
+	return cmd
+}
+
+func runBundleLoad(ctx context.Context, dockerCli command.Cli, backend api.Service, opts bundleLoadOptions, bundle string) error {
+	directory := opts.directory
+	if directory == "" {
+		// project name is only known once manifest has been read, so extract into a temporary location first
+		tmp, err := os.MkdirTemp(".", ".compose-bundle-")
+		if err != nil {
+			return err
+		}
+		directory = tmp
+	}
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := backend.Unbundle(ctx, api.UnbundleOptions{
+		Input:     bundle,
+		Directory: directory,
+	})
+	if err != nil {
+		if opts.directory == "" {
+			_ = os.RemoveAll(directory)
+		}
+		return err
+	}
+	if opts.directory == "" {
+		target, err := filepath.Abs(manifest.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(target); err == nil {
+			_ = os.RemoveAll(directory)
+			return fmt.Errorf("%s already exists, use --directory to select another location", target)
+		}
+		if err := os.Rename(directory, target); err != nil {
+			return err
+		}
+		directory = target
+	}
+	fmt.Fprintf(dockerCli.Err(), "Project files extracted into %s\n", directory)
+	if opts.noUp {
+		return nil
+	}
+
+	projectOptions := ProjectOptions{
+		ProjectName: manifest.Name,
+		ProjectDir:  directory,
+		Profiles:    manifest.Profiles,
+	}
+	for _, file := range manifest.ComposeFiles {
+		projectOptions.ConfigPaths = append(projectOptions.ConfigPaths, filepath.Join(directory, file))
+	}
+	if manifest.ImageDigests != "" {
+		projectOptions.ConfigPaths = append(projectOptions.ConfigPaths, filepath.Join(directory, manifest.ImageDigests))
+	}
+	for _, file := range manifest.EnvFiles {
+		projectOptions.EnvFiles = append(projectOptions.EnvFiles, filepath.Join(directory, file))
+	}
+	project, _, err := projectOptions.ToProject(ctx, dockerCli, nil)
+	if err != nil {
+		return err
+	}
+
+	// images have been loaded from bundle, registry must not be involved
+	var services []string
+	for _, image := range manifest.Images {
+		services = append(services, image.Service)
+	}
+	for name, service := range project.Services {
+		service.PullPolicy = types.PullPolicyNever
+		project.Services[name] = service
+	}
+
+	return backend.Up(ctx, project, api.UpOptions{
+		Create: api.CreateOptions{
+			Services:             services,
+			Recreate:             api.RecreateDiverged,
+			RecreateDependencies: api.RecreateDiverged,
+			Inherit:              true,
+		},
+		Start: api.StartOptions{
+			Project:  project,
+			Services: services,
+		},
+	})
+}
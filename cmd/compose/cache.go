@@ -0,0 +1,159 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/pkg/remote"
+)
+
+// cacheCommand groups commands managing the cache of remote git and OCI resources
+func cacheCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache [COMMAND]",
+		Short: "EXPERIMENTAL - Manage cached remote resources",
+	}
+	cmd.AddCommand(
+		cacheListCommand(dockerCli),
+		cachePruneCommand(dockerCli),
+		cacheRefreshCommand(dockerCli),
+	)
+	return cmd
+}
+
+type cacheListOptions struct {
+	format string
+	quiet  bool
+}
+
+func cacheListCommand(dockerCli command.Cli) *cobra.Command {
+	opts := cacheListOptions{}
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS]",
+		Aliases: []string{"list"},
+		Short:   "List cached remote resources",
+		Args:    cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runCacheList(dockerCli, opts)
+		}),
+	}
+	cmd.Flags().StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json]")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Only display cache keys")
+	return cmd
+}
+
+func runCacheList(dockerCli command.Cli, opts cacheListOptions) error {
+	entries, err := remote.ListCache()
+	if err != nil {
+		return err
+	}
+	if opts.quiet {
+		for _, e := range entries {
+			_, _ = fmt.Fprintln(dockerCli.Out(), e.Key)
+		}
+		return nil
+	}
+	return formatter.Print(entries, opts.format, dockerCli.Out(),
+		func(w io.Writer) {
+			for _, e := range entries {
+				source := e.Source
+				if source == "" {
+					source = "<unknown>"
+				}
+				lastUsed := units.HumanDuration(time.Since(e.LastUsed)) + " ago"
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Key[:min(12, len(e.Key))], e.Kind, source, units.HumanSize(float64(e.Size)), lastUsed)
+			}
+		},
+		"KEY", "TYPE", "SOURCE", "SIZE", "LAST USED")
+}
+
+type cachePruneOptions struct {
+	all       bool
+	olderThan time.Duration
+	maxSize   string
+}
+
+func cachePruneCommand(dockerCli command.Cli) *cobra.Command {
+	opts := cachePruneOptions{}
+	cmd := &cobra.Command{
+		Use:   "prune [OPTIONS]",
+		Short: "Remove cached remote resources",
+		Args:  cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runCachePrune(ctx, dockerCli, opts)
+		}),
+	}
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.all, "all", "a", false, "Remove all cached resources")
+	flags.DurationVar(&opts.olderThan, "older-than", 0, "Remove resources not used for this duration (e.g. 168h)")
+	flags.StringVar(&opts.maxSize, "max-size", "", "Remove least recently used resources until cache size is under this limit (e.g. 500MB)")
+	return cmd
+}
+
+func runCachePrune(ctx context.Context, dockerCli command.Cli, opts cachePruneOptions) error {
+	options := remote.PruneCacheOptions{
+		All:       opts.all,
+		OlderThan: opts.olderThan,
+	}
+	if opts.maxSize != "" {
+		size, err := units.RAMInBytes(opts.maxSize)
+		if err != nil {
+			return fmt.Errorf("invalid --max-size: %w", err)
+		}
+		options.MaxSize = size
+	}
+	if !options.All && options.OlderThan <= 0 && options.MaxSize <= 0 {
+		return errors.New("one of --all, --older-than or --max-size is required")
+	}
+
+	removed, err := remote.PruneCache(ctx, options)
+	var reclaimed int64
+	for _, e := range removed {
+		reclaimed += e.Size
+		_, _ = fmt.Fprintf(dockerCli.Out(), "Deleted: %s\n", e.Key)
+	}
+	_, _ = fmt.Fprintf(dockerCli.Out(), "Total reclaimed space: %s\n", units.HumanSize(float64(reclaimed)))
+	return err
+}
+
+func cacheRefreshCommand(dockerCli command.Cli) *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh REFERENCE...",
+		Short: "Resolve remote references again and update cached resources",
+		Long:  "Resolve git, OCI or HTTPS remote references again and update cached resources, so floating git branches, OCI tags and HTTPS files are updated",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			for _, ref := range args {
+				if err := remote.RefreshCache(ctx, dockerCli, ref); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(dockerCli.Out(), "Refreshed: %s\n", ref)
+			}
+			return nil
+		}),
+	}
+}
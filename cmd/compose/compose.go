@@ -28,6 +28,7 @@ import (
 	"syscall"
 
 	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/consts"
 	"github.com/compose-spec/compose-go/v2/dotenv"
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
@@ -67,6 +68,22 @@ const (
 	ComposeEnvFiles = "COMPOSE_ENV_FILES"
 	// ComposeMenu defines if the navigation menu should be rendered. Can be also set via --menu
 	ComposeMenu = "COMPOSE_MENU"
+	// ComposeTimings defines the format of the timings summary to report. Can be also set via --timings
+	ComposeTimings = "COMPOSE_TIMINGS"
+	// ComposeBuildCacheFrom defines additional build cache sources, separated by `;`. Can be also set via --cache-from
+	ComposeBuildCacheFrom = "COMPOSE_BUILD_CACHE_FROM"
+	// ComposeBuildCacheTo defines additional build cache destinations, separated by `;`. Can be also set via --cache-to
+	ComposeBuildCacheTo = "COMPOSE_BUILD_CACHE_TO"
+	// ComposeBuildCacheReplace defines if build cache entries replace the ones set by compose file. Can be also set via --cache-replace
+	ComposeBuildCacheReplace = "COMPOSE_BUILD_CACHE_REPLACE"
+	// ComposeBuildContextSizeWarning defines the build context size above which a warning is emitted, "0" disables it
+	ComposeBuildContextSizeWarning = "COMPOSE_BUILD_CONTEXT_SIZE_WARNING"
+	// ComposeLockFile defines the image lockfile to use. Can be also set via --lock-file
+	ComposeLockFile = "COMPOSE_LOCK_FILE"
+	// ComposeRegistryAttempts defines the number of attempts for pull and push operations failing with a transient error
+	ComposeRegistryAttempts = "COMPOSE_REGISTRY_ATTEMPTS"
+	// ComposeRegistryParallelLimit set the limit running concurrent pull or push operations against a single registry
+	ComposeRegistryParallelLimit = "COMPOSE_REGISTRY_PARALLEL_LIMIT"
 )
 
 type Backend interface {
@@ -115,6 +132,25 @@ func AdaptCmd(fn CobraCommand) func(cmd *cobra.Command, args []string) error {
 	}
 }
 
+// wrapRunEWithTimings makes the command report timings once it completes.
+//
+// PersistentPostRunE can't be used for this purpose because it only runs if RunE
+// does _not_ return an error, and is overridden by subcommands declaring their own.
+func wrapRunEWithTimings(c *cobra.Command, dockerCli command.Cli) {
+	runE := c.RunE
+	if runE == nil {
+		return
+	}
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		defer func() {
+			if err := ui.ReportTimings(dockerCli.Err()); err != nil {
+				logrus.Warnf("failed to report timings: %v", err)
+			}
+		}()
+		return runE(cmd, args)
+	}
+}
+
 // Adapt a Command func to cobra library
 func Adapt(fn Command) func(cmd *cobra.Command, args []string) error {
 	return AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
@@ -220,6 +256,7 @@ func (o *ProjectOptions) ToModel(ctx context.Context, dockerCli command.Cli, ser
 	for _, r := range remotes {
 		po = append(po, cli.WithResourceLoader(r))
 	}
+	po = append(po, withRemoteConfigFiles(ctx, remote.NewHTTPRemoteLoader(o.Offline)))
 
 	options, err := o.toProjectOptions(po...)
 	if err != nil {
@@ -239,6 +276,7 @@ func (o *ProjectOptions) ToProject(ctx context.Context, dockerCli command.Cli, s
 	for _, r := range remotes {
 		po = append(po, cli.WithResourceLoader(r))
 	}
+	po = append(po, withRemoteConfigFiles(ctx, remote.NewHTTPRemoteLoader(o.Offline)))
 
 	options, err := o.toProjectOptions(po...)
 	if err != nil {
@@ -277,13 +315,19 @@ func (o *ProjectOptions) ToProject(ctx context.Context, dockerCli command.Cli, s
 		return nil, metrics, compose.WrapComposeError(err)
 	}
 
+	project, err = o.prepareProject(project, services)
+	return project, metrics, err
+}
+
+// prepareProject enables selected services and sets compose labels on a freshly loaded project
+func (o *ProjectOptions) prepareProject(project *types.Project, services []string) (*types.Project, error) {
 	if project.Name == "" {
-		return nil, metrics, errors.New("project name can't be empty. Use `--project-name` to set a valid name")
+		return nil, errors.New("project name can't be empty. Use `--project-name` to set a valid name")
 	}
 
-	project, err = project.WithServicesEnabled(services...)
+	project, err := project.WithServicesEnabled(services...)
 	if err != nil {
-		return nil, metrics, err
+		return nil, err
 	}
 
 	for name, s := range project.Services {
@@ -305,17 +349,87 @@ func (o *ProjectOptions) ToProject(ctx context.Context, dockerCli command.Cli, s
 		project = project.WithoutUnnecessaryResources()
 	}
 
-	project, err = project.WithSelectedServices(services)
-	return project, metrics, err
+	return project.WithSelectedServices(services)
 }
 
 func (o *ProjectOptions) remoteLoaders(dockerCli command.Cli) []loader.ResourceLoader {
+	http := remote.NewHTTPRemoteLoader(o.Offline)
+	git := remote.NewGitRemoteLoader(dockerCli, o.Offline)
 	if o.Offline {
-		return nil
+		// git and HTTP loaders serve cached resources while offline
+		return []loader.ResourceLoader{git, http}
 	}
-	git := remote.NewGitRemoteLoader(o.Offline)
 	oci := remote.NewOCIRemoteLoader(dockerCli, o.Offline)
-	return []loader.ResourceLoader{git, oci}
+	return []loader.ResourceLoader{git, oci, http}
+}
+
+// withRemoteConfigFiles downloads compose files set by --file or COMPOSE_FILE from an HTTPS URL, so they get
+// loaded as local files. Relative paths in a remote compose file are resolved from current directory, unless
+// --project-directory is set. Git repositories and OCI artifacts are only supported by include and extends
+func withRemoteConfigFiles(ctx context.Context, httpLoader loader.ResourceLoader) cli.ProjectOptionsFn {
+	return func(o *cli.ProjectOptions) error {
+		if len(o.ConfigPaths) == 0 {
+			// cli.WithConfigFileEnv only accepts local files, so COMPOSE_FILE is resolved here when it sets a remote one
+			if err := cli.WithOsEnv(o); err != nil {
+				return err
+			}
+			paths, err := composeFileEnv(o.Environment, httpLoader.Accept)
+			if err != nil {
+				return err
+			}
+			o.ConfigPaths = paths
+		}
+		o.ConfigPaths = append([]string{}, o.ConfigPaths...)
+		for i, path := range o.ConfigPaths {
+			if !httpLoader.Accept(path) {
+				continue
+			}
+			local, err := httpLoader.Load(ctx, path)
+			if err != nil {
+				return err
+			}
+			if i == 0 && o.WorkingDir == "" {
+				wd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				o.WorkingDir = wd
+			}
+			o.ConfigPaths[i] = local
+		}
+		return nil
+	}
+}
+
+// composeFileEnv returns compose files set by COMPOSE_FILE if it includes a remote resource, local ones made absolute
+func composeFileEnv(env types.Mapping, isRemote func(string) bool) ([]string, error) {
+	f, ok := env[consts.ComposeFilePath]
+	if !ok {
+		return nil, nil
+	}
+	sep := env[consts.ComposePathSeparator]
+	if sep == "" {
+		sep = string(os.PathListSeparator)
+	}
+	paths := strings.Split(f, sep)
+	remote := false
+	for _, path := range paths {
+		remote = remote || isRemote(path)
+	}
+	if !remote {
+		return nil, nil
+	}
+	for i, path := range paths {
+		if path == "-" || isRemote(path) {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = abs
+	}
+	return paths, nil
 }
 
 func (o *ProjectOptions) toProjectOptions(po ...cli.ProjectOptionsFn) (*cli.ProjectOptions, error) {
@@ -354,12 +468,14 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 	experiments := experimental.NewState()
 	opts := ProjectOptions{}
 	var (
-		ansi     string
-		noAnsi   bool
-		verbose  bool
-		version  bool
-		parallel int
-		dryRun   bool
+		ansi        string
+		noAnsi      bool
+		verbose     bool
+		version     bool
+		parallel    int
+		dryRun      bool
+		timings     string
+		timingsFile string
 	)
 	c := &cobra.Command{
 		Short:            "Docker Compose",
@@ -448,6 +564,8 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 				ui.Mode = ui.ModePlain
 			case ui.ModeQuiet, "none":
 				ui.Mode = ui.ModeQuiet
+			case ui.ModeJSON:
+				ui.Mode = ui.ModeJSON
 			default:
 				return fmt.Errorf("unsupported --progress value %q", opts.Progress)
 			}
@@ -493,6 +611,29 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 				backend.MaxConcurrency(parallel)
 			}
 
+			registryOptions, err := registryOptionsFromEnv()
+			if err != nil {
+				return err
+			}
+			backend.ConfigureRegistry(registryOptions)
+
+			if v, ok := os.LookupEnv(ComposeTimings); ok && !composeCmd.Flags().Changed("timings") {
+				timings = v
+			}
+			switch timings {
+			case "", ui.TimingsText, ui.TimingsJSON, ui.TimingsTrace:
+				ui.Timings = timings
+			default:
+				return fmt.Errorf("unsupported --timings value %q", timings)
+			}
+			if timingsFile != "" && timings == "" {
+				ui.Timings = ui.TimingsJSON
+			}
+			ui.TimingsFile = timingsFile
+			if ui.Timings != "" {
+				wrapRunEWithTimings(cmd, dockerCli)
+			}
+
 			// (5) dry run detection
 			ctx, err = backend.DryRunMode(ctx, dryRun)
 			if err != nil {
@@ -584,6 +725,8 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 
 	c.Flags().StringVar(&ansi, "ansi", "auto", `Control when to print ANSI control characters ("never"|"always"|"auto")`)
 	c.Flags().IntVar(&parallel, "parallel", -1, `Control max parallelism, -1 for unlimited`)
+	c.Flags().StringVar(&timings, "timings", "", fmt.Sprintf(`Report time spent per resource and phase once command completes (%s)`, strings.Join([]string{ui.TimingsText, ui.TimingsJSON, ui.TimingsTrace}, ", ")))
+	c.Flags().StringVar(&timingsFile, "timings-file", "", "Write timings report to file instead of progress output")
 	c.Flags().BoolVarP(&version, "version", "v", false, "Show the Docker Compose version information")
 	c.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Execute command in dry run mode")
 	c.Flags().MarkHidden("version") //nolint:errcheck
@@ -624,6 +767,7 @@ var printerModes = []string{
 	ui.ModeTTY,
 	ui.ModePlain,
 	ui.ModeQuiet,
+	ui.ModeJSON,
 }
 
 func SetUnchangedOption(name string, experimentalFlag bool) bool {
@@ -637,3 +781,23 @@ func SetUnchangedOption(name string, experimentalFlag bool) bool {
 	}
 	return value
 }
+
+// registryOptionsFromEnv reads registry retry and concurrency settings from environment
+func registryOptionsFromEnv() (api.RegistryOptions, error) {
+	var options api.RegistryOptions
+	for name, value := range map[string]*int{
+		ComposeRegistryAttempts:      &options.Attempts,
+		ComposeRegistryParallelLimit: &options.MaxConcurrency,
+	} {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return options, fmt.Errorf("%s must be a positive integer (found: %q)", name, v)
+		}
+		*value = i
+	}
+	return options, nil
+}
@@ -17,10 +17,22 @@
 package compose
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/spf13/cobra"
+	"go.uber.org/mock/gomock"
 	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/mocks"
+	ui "github.com/docker/compose/v2/pkg/progress"
+	"github.com/docker/compose/v2/pkg/remote"
 )
 
 func TestFilterServices(t *testing.T) {
@@ -53,3 +65,92 @@ func TestFilterServices(t *testing.T) {
 	_, err = p.GetService("zot")
 	assert.NilError(t, err)
 }
+
+// fakeRemoteLoader serves compose files from a local directory for paths with a fake:// prefix
+type fakeRemoteLoader struct {
+	dir string
+}
+
+func (f fakeRemoteLoader) Accept(path string) bool {
+	return strings.HasPrefix(path, "fake://")
+}
+
+func (f fakeRemoteLoader) Load(_ context.Context, path string) (string, error) {
+	return filepath.Join(f.dir, strings.TrimPrefix(path, "fake://")), nil
+}
+
+func (f fakeRemoteLoader) Dir(path string) string {
+	return filepath.Dir(path)
+}
+
+func TestRemoteConfigFilesFromEnv(t *testing.T) {
+	remoteDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(remoteDir, "compose.yaml"), []byte("services:\n  web:\n    image: nginx\n"), 0o600))
+	localDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(localDir, "override.yaml"), []byte("services:\n  web:\n    image: httpd\n"), 0o600))
+	t.Setenv("COMPOSE_FILE", "fake://compose.yaml,"+filepath.Join(localDir, "override.yaml"))
+	t.Setenv("COMPOSE_PATH_SEPARATOR", ",")
+
+	o := ProjectOptions{ProjectName: "test"}
+	options, err := o.toProjectOptions(withRemoteConfigFiles(context.Background(), fakeRemoteLoader{dir: remoteDir}))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options.ConfigPaths, []string{
+		filepath.Join(remoteDir, "compose.yaml"),
+		filepath.Join(localDir, "override.yaml"),
+	})
+	// relative paths in a remote compose file are resolved from current directory
+	wd, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.Equal(t, options.WorkingDir, wd)
+
+	project, err := options.LoadProject(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, project.Services["web"].Image, "httpd")
+}
+
+func TestLocalConfigFilesFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services:\n  web:\n    image: nginx\n"), 0o600))
+	t.Setenv("COMPOSE_FILE", filepath.Join(dir, "compose.yaml"))
+
+	o := ProjectOptions{ProjectName: "test"}
+	options, err := o.toProjectOptions(withRemoteConfigFiles(context.Background(), fakeRemoteLoader{}))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options.ConfigPaths, []string{filepath.Join(dir, "compose.yaml")})
+	assert.Equal(t, options.WorkingDir, "")
+}
+
+func TestRemoteConfigFilesOnlyHTTPS(t *testing.T) {
+	paths := []string{"https://github.com/docker/compose.git#main:compose.yaml", "oci://docker.io/test/compose:latest"}
+	o := ProjectOptions{ProjectName: "test", ConfigPaths: paths}
+	options, err := o.toProjectOptions(withRemoteConfigFiles(context.Background(), remote.NewHTTPRemoteLoader(true)))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options.ConfigPaths, paths)
+}
+
+func TestTimingsReportedOnFailure(t *testing.T) {
+	defer func(format string) { ui.Timings = format }(ui.Timings)
+	ui.Timings = ui.TimingsText
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	stderr := &bytes.Buffer{}
+	cli := mocks.NewMockCli(ctrl)
+	cli.EXPECT().Err().Return(stderr).AnyTimes()
+
+	cmd := &cobra.Command{
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ui.Run(context.Background(), func(ctx context.Context) error {
+				w := ui.ContextWriter(ctx)
+				w.Event(ui.CreatingEvent("Container test"))
+				w.Event(ui.ErrorEvent("Container test"))
+				return errors.New("failed")
+			}, &bytes.Buffer{})
+		},
+	}
+	wrapRunEWithTimings(cmd, cli)
+
+	err := cmd.RunE(cmd, nil)
+	assert.Error(t, err, "failed")
+	assert.Assert(t, strings.Contains(stderr.String(), "Container test"), stderr.String())
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/compose"
 )
 
 type createOptions struct {
@@ -46,6 +47,8 @@ type createOptions struct {
 	timeout       int
 	quietPull     bool
 	scale         []string
+	locked        bool
+	lockFile      string
 }
 
 func createCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -74,12 +77,14 @@ func createCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service
 	flags := cmd.Flags()
 	flags.BoolVar(&opts.Build, "build", false, "Build images before starting containers")
 	flags.BoolVar(&opts.noBuild, "no-build", false, "Don't build an image, even if it's policy")
-	flags.StringVar(&opts.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"never"|"build")`)
+	flags.StringVar(&opts.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"never"|"build"|"daily"|"weekly"|"every_<duration>")`)
 	flags.BoolVar(&opts.quietPull, "quiet-pull", false, "Pull without printing progress information")
 	flags.BoolVar(&opts.forceRecreate, "force-recreate", false, "Recreate containers even if their configuration and image haven't changed")
 	flags.BoolVar(&opts.noRecreate, "no-recreate", false, "If containers already exist, don't recreate them. Incompatible with --force-recreate.")
 	flags.BoolVar(&opts.removeOrphans, "remove-orphans", false, "Remove containers for services not defined in the Compose file")
 	flags.StringArrayVar(&opts.scale, "scale", []string{}, "Scale SERVICE to NUM instances. Overrides the `scale` setting in the Compose file if present.")
+	flags.BoolVar(&opts.locked, "locked", false, "Use image digests recorded in lockfile, fail if an image is not locked")
+	flags.StringVar(&opts.lockFile, "lock-file", "", fmt.Sprintf("Lockfile used by --locked (default to %s in project directory, or %s)", LockFileName, ComposeLockFile))
 	return cmd
 }
 
@@ -165,6 +170,12 @@ func (opts createOptions) Apply(project *types.Project) error {
 		return err
 	}
 
+	if opts.locked {
+		if err := applyImageLock(project, opts.lockFile); err != nil {
+			return err
+		}
+	}
+
 	err := applyScaleOpts(project, opts.scale)
 	if err != nil {
 		return err
@@ -194,5 +205,9 @@ func applyScaleOpts(project *types.Project, opts []string) error {
 func (opts createOptions) isPullPolicyValid() bool {
 	pullPolicies := []string{types.PullPolicyAlways, types.PullPolicyNever, types.PullPolicyBuild,
 		types.PullPolicyMissing, types.PullPolicyIfNotPresent}
-	return slices.Contains(pullPolicies, opts.Pull)
+	if slices.Contains(pullPolicies, opts.Pull) {
+		return true
+	}
+	_, ok, err := compose.PullInterval(opts.Pull)
+	return ok && err == nil
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/docker/go-units"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -119,6 +120,8 @@ func defaultBuildOptions() api.BuildOptions {
 	return api.BuildOptions{
 		Args:     make(types.MappingWithEquals),
 		Progress: "auto",
+
+		ContextSizeWarning: 500 * units.MB,
 	}
 }
 
@@ -0,0 +1,78 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type exportOptions struct {
+	*ProjectOptions
+	output string
+}
+
+func exportCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := exportOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "export [OPTIONS] [SERVICE...]",
+		Short: "EXPERIMENTAL - Convert compose application into Kubernetes manifests",
+		Long: `Convert compose application into Kubernetes manifests.
+
+Services are converted into a Deployment, or a StatefulSet when using named volumes, and a Service for published
+or exposed ports. Configs and secrets are converted into ConfigMaps and Secrets, and named volumes into
+PersistentVolumeClaims. A warning is reported for each attribute which can't be converted.
+
+Each service is written to a file named after its Kubernetes name. PersistentVolumeClaims, ConfigMaps and Secrets
+are shared by services, and written to _volumes.yaml, _configs.yaml and _secrets.yaml. Export fails when
+services, volumes, configs or secrets have names converting to the same Kubernetes name, like web_app and web-app.`,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runExport(ctx, dockerCli, backend, opts, args)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "kubernetes", "Directory to write manifests to")
+	return cmd
+}
+
+func runExport(ctx context.Context, dockerCli command.Cli, backend api.Service, opts exportOptions, services []string) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+
+	warnings, err := backend.Export(ctx, project, api.ExportOptions{
+		Services: services,
+		Output:   opts.output,
+	})
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		logrus.Warn(w)
+	}
+	_, err = fmt.Fprintf(dockerCli.Out(), "Kubernetes manifests written to %s\n", opts.output)
+	return err
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/remote"
+)
+
+type fetchOptions struct {
+	output string
+	force  bool
+}
+
+func fetchCommand(dockerCli command.Cli) *cobra.Command {
+	opts := fetchOptions{}
+	cmd := &cobra.Command{
+		Use:   "fetch [OPTIONS] REFERENCE",
+		Short: "EXPERIMENTAL - Download a published compose application into a local directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runFetch(ctx, dockerCli, opts, args[0])
+		}),
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&opts.output, "output", "o", "", "Directory to write project files to (default to repository name)")
+	flags.BoolVar(&opts.force, "force", false, "Overwrite existing files")
+	return cmd
+}
+
+func runFetch(ctx context.Context, dockerCli command.Cli, opts fetchOptions, ref string) error {
+	ref = strings.TrimPrefix(ref, "oci://")
+	dir := opts.output
+	if dir == "" {
+		named, err := reference.ParseNormalizedNamed(ref)
+		if err != nil {
+			return err
+		}
+		dir = path.Base(reference.Path(named))
+	}
+
+	metadata, err := remote.FetchOCIArtifact(ctx, dockerCli, ref, dir, opts.force)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(dockerCli.Out(), "Fetched %s (%s) into %s\n", metadata.Reference, metadata.Digest, dir)
+	return err
+}
@@ -0,0 +1,243 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/internal"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/compose"
+)
+
+type lintOptions struct {
+	*ProjectOptions
+	format   string
+	severity []string
+	failOn   string
+}
+
+func lintCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := lintOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "lint [OPTIONS] [SERVICE...]",
+		Short: "EXPERIMENTAL - Check compose model for risky or sloppy configuration",
+		Long: fmt.Sprintf(`Check compose model for risky or sloppy configuration.
+
+A rule can be disabled for a service, or for the whole project, by listing its ID in a %s extension.`, compose.LintIgnoreExtension),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runLint(ctx, dockerCli, backend, opts, args)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json | sarif]")
+	flags.StringArrayVar(&opts.severity, "severity", []string{}, `Set severity of a rule, as RULE=LEVEL with level one of "error", "warning", "note" or "off"`)
+	flags.StringVar(&opts.failOn, "fail-on", string(api.LintSeverityError), `Exit with an error status when a finding has this severity or higher ("error"|"warning"|"note"|"never")`)
+	return cmd
+}
+
+func runLint(ctx context.Context, dockerCli command.Cli, backend api.Service, opts lintOptions, services []string) error {
+	failOn, ok := lintSeverityRank[api.LintSeverity(opts.failOn)]
+	if !ok && opts.failOn != "never" {
+		return fmt.Errorf("invalid --fail-on value %q", opts.failOn)
+	}
+
+	severity := map[string]api.LintSeverity{}
+	for _, s := range opts.severity {
+		rule, level, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("invalid --severity %q, expected RULE=LEVEL", s)
+		}
+		severity[rule] = api.LintSeverity(level)
+	}
+
+	project, _, err := opts.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+
+	findings, err := backend.Lint(ctx, project, api.LintOptions{
+		Services: services,
+		Severity: severity,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch opts.format {
+	case "sarif":
+		var file string
+		if len(project.ComposeFiles) > 0 {
+			file = project.ComposeFiles[0]
+			if rel, err := filepath.Rel(project.WorkingDir, file); err == nil {
+				file = rel
+			}
+		}
+		err = printSarif(dockerCli.Out(), findings, filepath.ToSlash(file))
+	default:
+		err = formatter.Print(findings, opts.format, dockerCli.Out(),
+			func(w io.Writer) {
+				for _, f := range findings {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Service, f.Severity, f.Rule, f.Message)
+				}
+			},
+			"SERVICE", "SEVERITY", "RULE", "MESSAGE")
+	}
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, f := range findings {
+		if ok && lintSeverityRank[f.Severity] >= failOn {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return cli.StatusError{StatusCode: 1, Status: fmt.Sprintf("%d lint finding(s) with severity %s or higher", failed, opts.failOn)}
+	}
+	return nil
+}
+
+var lintSeverityRank = map[api.LintSeverity]int{
+	api.LintSeverityNote:    1,
+	api.LintSeverityWarning: 2,
+	api.LintSeverityError:   3,
+}
+
+// sarifLog is the subset of SARIF 2.1.0 format used to report lint findings
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	DefaultConfiguration sarifRuleDefaults `json:"defaultConfiguration"`
+}
+
+type sarifRuleDefaults struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func printSarif(out io.Writer, findings []api.LintFinding, file string) error {
+	driver := sarifDriver{
+		Name:           "docker compose",
+		Version:        internal.Version,
+		InformationURI: "https://docs.docker.com/compose/",
+	}
+	for _, r := range compose.LintRules() {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifRuleDefaults{Level: string(r.Severity)},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, f := range findings {
+		location := sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{
+				FullyQualifiedName: "services." + f.Service,
+				Kind:               "object",
+			}},
+		}
+		if file != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: file},
+			}
+		}
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     string(f.Severity),
+			Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", f.Service, f.Message)},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	})
+}
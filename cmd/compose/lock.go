@@ -0,0 +1,207 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/docker/compose/v2/pkg/compose"
+)
+
+// LockFileName is the name of the image lockfile, stored next to the compose file
+const LockFileName = "compose.lock"
+
+// imageLockVersion is the version of the lockfile format
+const imageLockVersion = 1
+
+// imageLock maps image references used by services to the digest they resolved to
+type imageLock struct {
+	Version int                    `yaml:"version"`
+	Images  map[string]lockedImage `yaml:"images"`
+}
+
+type lockedImage struct {
+	// Digest is the digest the image reference resolved to, i.e. the image index for multi-platform images
+	Digest string `yaml:"digest"`
+}
+
+type lockOptions struct {
+	*ProjectOptions
+	output string
+}
+
+func lockCommand(p *ProjectOptions, dockerCli command.Cli) *cobra.Command {
+	opts := lockOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "lock [OPTIONS]",
+		Short: "EXPERIMENTAL - Resolve service images to digests and write them to a lockfile",
+		Args:  cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runLock(ctx, dockerCli, opts)
+		}),
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", fmt.Sprintf("Lockfile to write (default to %s in project directory, or %s)", LockFileName, ComposeLockFile))
+	return cmd
+}
+
+func runLock(ctx context.Context, dockerCli command.Cli, opts lockOptions) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, nil)
+	if err != nil {
+		return err
+	}
+	project, err = project.WithProfiles([]string{"*"})
+	if err != nil {
+		return err
+	}
+
+	resolver := compose.ImageDigestResolver(ctx, dockerCli.ConfigFile(), dockerCli.Client())
+	lock := imageLock{
+		Version: imageLockVersion,
+		Images:  map[string]lockedImage{},
+	}
+	for _, service := range project.Services {
+		if service.Image == "" || service.Build != nil {
+			// locally built images can't be locked
+			continue
+		}
+		named, err := parseImageRef(service.Image)
+		if err != nil {
+			return err
+		}
+		key := lockKey(named)
+		if _, ok := lock.Images[key]; ok {
+			continue
+		}
+
+		var resolved digest.Digest
+		if canonical, ok := named.(reference.Canonical); ok {
+			resolved = canonical.Digest()
+		} else {
+			resolved, err = resolver(named)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", service.Image, err)
+			}
+		}
+		lock.Images[key] = lockedImage{Digest: resolved.String()}
+	}
+
+	content, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lockFilePath(project, opts.output), content, 0o666)
+}
+
+// parseImageRef parses an image reference, keeping both tag and digest when set
+func parseImageRef(image string) (reference.Named, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, err
+	}
+	return reference.TagNameOnly(named), nil
+}
+
+// lockKey is the reference, without digest, used to index an image in lockfile
+func lockKey(named reference.Named) string {
+	return reference.FamiliarString(withoutDigest(named))
+}
+
+func withoutDigest(named reference.Named) reference.Named {
+	if tagged, ok := named.(reference.Tagged); ok {
+		if t, err := reference.WithTag(reference.TrimNamed(named), tagged.Tag()); err == nil {
+			return t
+		}
+	}
+	return reference.TrimNamed(named)
+}
+
+// lockFilePath returns the lockfile to use, as set by flag or COMPOSE_LOCK_FILE, defaulting to compose.lock in project directory
+func lockFilePath(project *types.Project, path string) string {
+	if path == "" {
+		path = os.Getenv(ComposeLockFile)
+	}
+	if path == "" {
+		path = filepath.Join(project.WorkingDir, LockFileName)
+	}
+	return path
+}
+
+func loadImageLock(path string) (imageLock, error) {
+	var lock imageLock
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, fmt.Errorf("no lockfile found at %s, run `docker compose alpha lock` to create one", path)
+		}
+		return lock, err
+	}
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return lock, fmt.Errorf("invalid lockfile %s: %w", path, err)
+	}
+	if lock.Version != imageLockVersion {
+		return lock, fmt.Errorf("unsupported lockfile version %d", lock.Version)
+	}
+	return lock, nil
+}
+
+// applyImageLock pins service images to the digests recorded in lockfile, so that only images
+// matching those can be pulled or used. See lockFilePath for lockfile resolution
+func applyImageLock(project *types.Project, lockFile string) error {
+	lock, err := loadImageLock(lockFilePath(project, lockFile))
+	if err != nil {
+		return err
+	}
+	for name, service := range project.Services {
+		if service.Image == "" || service.Build != nil {
+			continue
+		}
+		named, err := parseImageRef(service.Image)
+		if err != nil {
+			return err
+		}
+		locked, ok := lock.Images[lockKey(named)]
+		if !ok {
+			return fmt.Errorf("service %q: image %s is not locked, run `docker compose alpha lock` to update lockfile", name, service.Image)
+		}
+		if canonical, ok := named.(reference.Canonical); ok && canonical.Digest().String() != locked.Digest {
+			return fmt.Errorf("service %q: image %s does not match locked digest %s", name, service.Image, locked.Digest)
+		}
+		d, err := digest.Parse(locked.Digest)
+		if err != nil {
+			return fmt.Errorf("service %q: invalid locked digest: %w", name, err)
+		}
+		pinned, err := reference.WithDigest(withoutDigest(named), d)
+		if err != nil {
+			return err
+		}
+		service.Image = pinned.String()
+		project.Services[name] = service
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+const lockedDigest = "sha256:0fc3ba4e1f3bb6fb4e5bd2c1d1b8dd4a1c6f5d0b7d9ad0c1e6bc52e3f3e0a111"
+
+func TestApplyImageLock(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, LockFileName), []byte(`version: 1
+images:
+  nginx:1.25:
+    digest: `+lockedDigest+`
+`), 0o644))
+
+	project := &types.Project{
+		WorkingDir: dir,
+		Services: types.Services{
+			"web":   {Name: "web", Image: "nginx:1.25"},
+			"app":   {Name: "app", Image: "app", Build: &types.BuildConfig{Context: "."}},
+			"other": {Name: "other"},
+		},
+	}
+	assert.NilError(t, applyImageLock(project, ""))
+	assert.Equal(t, project.Services["web"].Image, "docker.io/library/nginx:1.25@"+lockedDigest)
+	assert.Equal(t, project.Services["app"].Image, "app")
+
+	project.Services["db"] = types.ServiceConfig{Name: "db", Image: "postgres"}
+	assert.ErrorContains(t, applyImageLock(project, ""), `service "db": image postgres is not locked`)
+
+	project.Services["db"] = types.ServiceConfig{Name: "db", Image: "nginx:1.25@sha256:1111111111111111111111111111111111111111111111111111111111111111"}
+	assert.ErrorContains(t, applyImageLock(project, ""), "does not match locked digest")
+}
+
+func TestApplyImageLockMissingFile(t *testing.T) {
+	project := &types.Project{WorkingDir: t.TempDir()}
+	assert.ErrorContains(t, applyImageLock(project, ""), "no lockfile found")
+}
+
+func TestApplyImageLockFile(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "images.lock")
+	assert.NilError(t, os.WriteFile(lockFile, []byte(`version: 1
+images:
+  nginx:1.25:
+    digest: `+lockedDigest+`
+`), 0o644))
+
+	newProject := func() *types.Project {
+		return &types.Project{
+			WorkingDir: t.TempDir(),
+			Services: types.Services{
+				"web": {Name: "web", Image: "nginx:1.25"},
+			},
+		}
+	}
+
+	project := newProject()
+	assert.NilError(t, applyImageLock(project, lockFile))
+	assert.Equal(t, project.Services["web"].Image, "docker.io/library/nginx:1.25@"+lockedDigest)
+
+	t.Setenv(ComposeLockFile, lockFile)
+	project = newProject()
+	assert.NilError(t, applyImageLock(project, ""))
+	assert.Equal(t, project.Services["web"].Image, "docker.io/library/nginx:1.25@"+lockedDigest)
+}
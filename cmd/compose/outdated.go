@@ -0,0 +1,104 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type outdatedOptions struct {
+	*ProjectOptions
+	all    bool
+	format string
+}
+
+func outdatedCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := outdatedOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "outdated [OPTIONS] [SERVICE...]",
+		Short: "EXPERIMENTAL - List services with a newer image available on registry",
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runOutdated(ctx, dockerCli, backend, opts, args)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	cmd.Flags().StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json]")
+	cmd.Flags().BoolVarP(&opts.all, "all", "a", false, "Also list services with an up-to-date image")
+	return cmd
+}
+
+func runOutdated(ctx context.Context, dockerCli command.Cli, backend api.Service, opts outdatedOptions, services []string) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+
+	updates, err := backend.Outdated(ctx, project, api.OutdatedOptions{
+		Services: services,
+	})
+	if err != nil {
+		return err
+	}
+	if !opts.all {
+		updates = filterImageUpdates(updates)
+	}
+
+	return formatter.Print(updates, opts.format, dockerCli.Out(),
+		func(w io.Writer) {
+			for _, u := range updates {
+				status := u.Status
+				if u.Error != "" {
+					status = fmt.Sprintf("%s (%s)", status, u.Error)
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", u.Service, u.Image, shortDigest(u.LocalDigest), shortDigest(u.RemoteDigest), status)
+			}
+		},
+		"SERVICE", "IMAGE", "LOCAL", "REMOTE", "STATUS")
+}
+
+// filterImageUpdates removes services already running the latest image
+func filterImageUpdates(updates []api.ImageUpdate) []api.ImageUpdate {
+	filtered := []api.ImageUpdate{}
+	for _, u := range updates {
+		if u.Status != api.ImageUpToDate {
+			filtered = append(filtered, u)
+		}
+	}
+	return filtered
+}
+
+func shortDigest(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	d, err := digest.Parse(s)
+	if err != nil {
+		return s
+	}
+	return d.Encoded()[:12]
+}
@@ -18,8 +18,16 @@ package compose
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/cli/cli/command"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/docker/compose/v2/pkg/api"
@@ -28,7 +36,10 @@ import (
 type publishOptions struct {
 	*ProjectOptions
 	resolveImageDigests bool
+	assumeYes           bool
 	ociVersion          string
+	withResources       bool
+	attestationOptions
 }
 
 func publishCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -45,18 +56,137 @@ func publishCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Servic
 	}
 	flags := cmd.Flags()
 	flags.BoolVar(&opts.resolveImageDigests, "resolve-image-digests", false, "Pin image tags to digests")
-	flags.StringVar(&opts.ociVersion, "oci-version", "", "OCI Image/Artifact specification version (automatically determined by default)")
+	flags.BoolVarP(&opts.assumeYes, "yes", "y", false, "Don't ask to confirm publishing files that look like they contain secrets")
+	flags.BoolVar(&opts.withResources, "with-resources", false, "Also publish included and extended compose files, env files and file-based configs. Requires recent Compose clients to consume the project")
+	flags.StringVar(&opts.ociVersion, "oci-version", string(api.OCIVersionAuto), `OCI image/artifact specification version ("auto"|"1.0"|"1.1"), "auto" falls back to 1.0 when registry rejects 1.1`)
+	opts.attestationOptions.addFlags(flags)
 	return cmd
 }
 
 func runPublish(ctx context.Context, dockerCli command.Cli, backend api.Service, opts publishOptions, repository string) error {
-	project, _, err := opts.ToProject(ctx, dockerCli, nil)
+	switch api.OCIVersion(opts.ociVersion) {
+	case api.OCIVersionAuto, api.OCIVersion1_0, api.OCIVersion1_1:
+	default:
+		return fmt.Errorf("unsupported --oci-version %q", opts.ociVersion)
+	}
+
+	collector := newModelFilesCollector(opts.remoteLoaders(dockerCli))
+	project, _, err := opts.ToProject(ctx, dockerCli, nil, func(o *cli.ProjectOptions) error {
+		o.WithListeners(collector.listen)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
 	return backend.Publish(ctx, project, repository, api.PublishOptions{
 		ResolveImageDigests: opts.resolveImageDigests,
+		ModelFiles:          collector.files(project),
+		AssumeYes:           opts.assumeYes,
+		WithResources:       opts.withResources,
 		OCIVersion:          api.OCIVersion(opts.ociVersion),
+		Attestations:        opts.attestations(),
 	})
 }
+
+// modelFilesCollector tracks local compose files included or extended while loading project, so they can be
+// published or bundled with it
+type modelFilesCollector struct {
+	remotes  []loader.ResourceLoader
+	includes []string
+	extends  []string
+}
+
+func newModelFilesCollector(remotes []loader.ResourceLoader) *modelFilesCollector {
+	return &modelFilesCollector{remotes: remotes}
+}
+
+func (c *modelFilesCollector) listen(event string, metadata map[string]any) {
+	switch event {
+	case "include":
+		workingDir, _ := metadata["workingdir"].(string)
+		paths, _ := metadata["path"].(types.StringList)
+		for _, path := range paths {
+			if c.isRemote(path) {
+				continue
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(workingDir, path)
+			}
+			c.includes = append(c.includes, path)
+		}
+	case "extends":
+		if path, ok := metadata["file"].(string); ok && !c.isRemote(path) {
+			c.extends = append(c.extends, path)
+		}
+	}
+}
+
+func (c *modelFilesCollector) isRemote(path string) bool {
+	for _, r := range c.remotes {
+		if r.Accept(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// files returns the collected files. Loader events don't tell which compose file declared an extended one,
+// so relative paths are resolved against the directories of the compose files making the model, including
+// the ones extended by the model, the way the loader resolves them against the declaring file's directory
+func (c *modelFilesCollector) files(project *types.Project) []string {
+	files := append([]string{}, c.includes...)
+	var dirs []string
+	addDir := func(file string) {
+		dir := filepath.Dir(file)
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, file := range project.ComposeFiles {
+		addDir(file)
+	}
+	for _, file := range c.includes {
+		addDir(file)
+	}
+	addDir(filepath.Join(project.WorkingDir, "compose.yaml"))
+
+	pending := c.extends
+	for len(pending) > 0 {
+		var unresolved []string
+		for _, path := range pending {
+			resolved, ok := resolveExtendedFile(path, dirs)
+			if !ok {
+				unresolved = append(unresolved, path)
+				continue
+			}
+			if !slices.Contains(files, resolved) {
+				files = append(files, resolved)
+			}
+			// file extended by this one are declared relative to its own directory
+			addDir(resolved)
+		}
+		if len(unresolved) == len(pending) {
+			for _, path := range unresolved {
+				logrus.Warnf("unable to locate extended file %s, it is skipped", path)
+			}
+			break
+		}
+		pending = unresolved
+	}
+	return files
+}
+
+func resolveExtendedFile(path string, dirs []string) (string, bool) {
+	if filepath.IsAbs(path) {
+		_, err := os.Stat(path)
+		return path, err == nil
+	}
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, path)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+	}
+	return "", false
+}
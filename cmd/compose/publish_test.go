@@ -0,0 +1,86 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"gotest.tools/v3/assert"
+)
+
+func TestModelFilesCollector(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	composeFile := write("compose.yaml", `
+include:
+  - infra/compose.yaml
+services:
+  app:
+    extends:
+      file: common/base.yaml
+      service: base
+`)
+	included := write("infra/compose.yaml", `
+services:
+  db:
+    extends:
+      file: db.yaml
+      service: db
+`)
+	base := write("common/base.yaml", `
+services:
+  base:
+    extends:
+      file: shared.yaml
+      service: shared
+`)
+	shared := write("common/shared.yaml", `
+services:
+  shared:
+    image: alpine
+`)
+	db := write("infra/db.yaml", `
+services:
+  db:
+    image: postgres
+`)
+
+	collector := newModelFilesCollector(nil)
+	options, err := cli.NewProjectOptions([]string{composeFile}, cli.WithName("test"), func(o *cli.ProjectOptions) error {
+		o.WithListeners(collector.listen)
+		return nil
+	})
+	assert.NilError(t, err)
+	project, err := options.LoadProject(context.Background())
+	assert.NilError(t, err)
+
+	files := collector.files(project)
+	assert.Equal(t, len(files), 4, "%v", files)
+	for _, expected := range []string{included, base, shared, db} {
+		assert.Assert(t, slices.Contains(files, expected), "%s not in %v", expected, files)
+	}
+}
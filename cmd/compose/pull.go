@@ -39,6 +39,8 @@ type pullOptions struct {
 	ignorePullFailures bool
 	noBuildable        bool
 	policy             string
+	locked             bool
+	lockFile           string
 }
 
 func pullCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -68,7 +70,9 @@ func pullCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.MarkHidden("no-parallel") //nolint:errcheck
 	cmd.Flags().BoolVar(&opts.ignorePullFailures, "ignore-pull-failures", false, "Pull what it can and ignores images with pull failures")
 	cmd.Flags().BoolVar(&opts.noBuildable, "ignore-buildable", false, "Ignore images that can be built")
-	cmd.Flags().StringVar(&opts.policy, "policy", "", `Apply pull policy ("missing"|"always")`)
+	cmd.Flags().StringVar(&opts.policy, "policy", "", `Apply pull policy ("missing"|"always"|"daily"|"weekly"|"every_<duration>")`)
+	cmd.Flags().BoolVar(&opts.locked, "locked", false, "Pull image digests recorded in lockfile, fail if an image is not locked")
+	cmd.Flags().StringVar(&opts.lockFile, "lock-file", "", fmt.Sprintf("Lockfile used by --locked (default to %s in project directory, or %s)", LockFileName, ComposeLockFile))
 	return cmd
 }
 
@@ -90,6 +94,12 @@ func (opts pullOptions) apply(project *types.Project, services []string) (*types
 			project.Services[i] = service
 		}
 	}
+
+	if opts.locked {
+		if err := applyImageLock(project, opts.lockFile); err != nil {
+			return nil, err
+		}
+	}
 	return project, nil
 }
 
@@ -0,0 +1,143 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/internal/memnet"
+	"github.com/docker/compose/v2/internal/server"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type serveOptions struct {
+	*ProjectOptions
+	endpoint string
+}
+
+func serveCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := serveOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "serve [OPTIONS]",
+		Short: "EXPERIMENTAL - Expose compose operations over a local HTTP API",
+		Args:  cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runServe(ctx, dockerCli, backend, opts)
+		}),
+	}
+	cmd.Flags().StringVar(&opts.endpoint, "endpoint", "", "Endpoint to listen on (unix:// or npipe://, defaults to a socket in temporary directory)")
+	return cmd
+}
+
+func defaultServeEndpoint() string {
+	if runtime.GOOS == "windows" {
+		return "npipe:////./pipe/docker_compose"
+	}
+	return "unix://" + filepath.Join(os.TempDir(), "docker-compose.sock")
+}
+
+func runServe(ctx context.Context, dockerCli command.Cli, backend api.Service, opts serveOptions) error {
+	if opts.endpoint == "" {
+		opts.endpoint = defaultServeEndpoint()
+	}
+	if addr, ok := strings.CutPrefix(opts.endpoint, "unix://"); ok {
+		if conn, err := net.DialTimeout("unix", addr, time.Second); err == nil {
+			_ = conn.Close()
+			return fmt.Errorf("%s is already in use by another process", opts.endpoint)
+		}
+		// remove stale socket left by a previous instance
+		if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		defer os.Remove(addr) //nolint:errcheck
+	}
+	listener, err := memnet.ListenEndpoint(opts.endpoint)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Listening on %s", opts.endpoint)
+
+	srv := server.New(backend, func(ctx context.Context, request server.ProjectRequest, services []string) (*types.Project, error) {
+		return loadServedProject(ctx, dockerCli, opts.ProjectOptions, request, services)
+	})
+	return srv.Serve(ctx, listener)
+}
+
+// loadServedProject loads the project described by a server request, either from compose files or from an inline model
+func loadServedProject(ctx context.Context, dockerCli command.Cli, defaults *ProjectOptions, request server.ProjectRequest, services []string) (*types.Project, error) {
+	o := &ProjectOptions{
+		ProjectName:   request.Name,
+		ConfigPaths:   request.ConfigPaths,
+		ProjectDir:    request.WorkingDir,
+		Profiles:      request.Profiles,
+		EnvFiles:      request.EnvFiles,
+		Compatibility: defaults.Compatibility,
+		Offline:       defaults.Offline,
+		All:           defaults.All,
+	}
+	if request.Model == nil {
+		project, _, err := o.ToProject(ctx, dockerCli, services, cli.WithResolvedPaths(true), cli.WithDiscardEnvFile)
+		return project, err
+	}
+
+	workingDir := request.WorkingDir
+	if workingDir == "" {
+		return nil, fmt.Errorf("working_dir is required to load an inline model")
+	}
+	options, err := o.toProjectOptions(cli.WithResolvedPaths(true), cli.WithDiscardEnvFile)
+	if err != nil {
+		return nil, err
+	}
+	project, err := loader.LoadWithContext(ctx, types.ConfigDetails{
+		WorkingDir: workingDir,
+		ConfigFiles: []types.ConfigFile{{
+			Filename: filepath.Join(workingDir, "compose.yaml"),
+			Config:   request.Model,
+		}},
+		Environment: options.Environment,
+	}, func(lo *loader.Options) {
+		if request.Name != "" {
+			lo.SetProjectName(request.Name, true)
+		} else {
+			lo.SetProjectName(loader.NormalizeProjectName(filepath.Base(workingDir)), false)
+		}
+		lo.ResolvePaths = true
+		lo.Profiles = request.Profiles
+		for _, r := range o.remoteLoaders(dockerCli) {
+			lo.ResourceLoaders = append(lo.ResourceLoaders, r)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o.prepareProject(project, services)
+}
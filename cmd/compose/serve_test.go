@@ -0,0 +1,52 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestServeRefusesSocketInUse(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket")
+	}
+	// keep path short, unix socket paths are limited to ~100 chars
+	dir, err := os.MkdirTemp("", "serve")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	addr := filepath.Join(dir, "compose.sock")
+	listener, err := net.Listen("unix", addr)
+	assert.NilError(t, err)
+	defer listener.Close() //nolint:errcheck
+
+	err = runServe(context.TODO(), nil, nil, serveOptions{
+		ProjectOptions: &ProjectOptions{},
+		endpoint:       "unix://" + addr,
+	})
+	assert.ErrorContains(t, err, "already in use")
+
+	_, err = os.Stat(addr)
+	assert.NilError(t, err)
+}
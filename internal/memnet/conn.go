@@ -48,3 +48,28 @@ func Dial(ctx context.Context, network, addr string) (net.Conn, error) {
 		return nil, fmt.Errorf("unsupported network: %s", network)
 	}
 }
+
+func ListenEndpoint(endpoint string) (net.Listener, error) {
+	if addr, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		return Listen("unix", addr)
+	}
+	if addr, ok := strings.CutPrefix(endpoint, "npipe://"); ok {
+		return Listen("npipe", addr)
+	}
+	return nil, fmt.Errorf("unsupported protocol for address: %s", endpoint)
+}
+
+func Listen(network, addr string) (net.Listener, error) {
+	switch network {
+	case "unix":
+		if err := validateSocketPath(addr); err != nil {
+			return nil, err
+		}
+		return net.Listen("unix", addr)
+	case "npipe":
+		// N.B. this will return an error on non-Windows
+		return listenNamedPipe(addr)
+	default:
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+}
@@ -31,6 +31,10 @@ func dialNamedPipe(_ context.Context, _ string) (net.Conn, error) {
 	return nil, fmt.Errorf("named pipes are only available on Windows")
 }
 
+func listenNamedPipe(_ string) (net.Listener, error) {
+	return nil, fmt.Errorf("named pipes are only available on Windows")
+}
+
 func validateSocketPath(addr string) error {
 	if len(addr) > maxUnixSocketPathSize {
 		return fmt.Errorf("socket address is too long: %s", addr)
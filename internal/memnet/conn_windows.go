@@ -27,6 +27,10 @@ func dialNamedPipe(ctx context.Context, addr string) (net.Conn, error) {
 	return winio.DialPipeContext(ctx, addr)
 }
 
+func listenNamedPipe(addr string) (net.Listener, error) {
+	return winio.ListenPipe(addr, nil)
+}
+
 func validateSocketPath(addr string) error {
 	// AF_UNIX sockets do not have strict path limits on Windows
 	return nil
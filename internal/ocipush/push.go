@@ -41,6 +41,18 @@ const (
 	// ComposeYAMLMediaType is the media type for each layer (Compose file)
 	// in the image manifest.
 	ComposeYAMLMediaType = "application/vnd.docker.compose.file+yaml"
+	// ComposeEnvFileMediaType is the media type for layers holding an env file
+	// the project depends on.
+	ComposeEnvFileMediaType = "application/vnd.docker.compose.envfile"
+	// ComposeResourceMediaType is the media type for layers holding any other
+	// local file the project depends on, like included or extended Compose
+	// files and file-based configs.
+	ComposeResourceMediaType = "application/vnd.docker.compose.resource"
+	// ComposeFileAnnotation is the layer annotation for the file name.
+	ComposeFileAnnotation = "com.docker.compose.file"
+	// ComposePathAnnotation is the layer annotation for the file path,
+	// relative to the project directory, used to restore the project layout.
+	ComposePathAnnotation = "com.docker.compose.path"
 	// ComposeEmptyConfigMediaType is a media type used for the config descriptor
 	// when doing OCI 1.0-style pushes.
 	//
@@ -76,42 +88,63 @@ func DescriptorForComposeFile(path string, content []byte) v1.Descriptor {
 		Size:      int64(len(content)),
 		Annotations: map[string]string{
 			"com.docker.compose.version": api.ComposeVersion,
-			"com.docker.compose.file":    filepath.Base(path),
+			ComposeFileAnnotation:        filepath.Base(path),
 		},
 	}
 }
 
+// DescriptorForResource creates the layer descriptor for a local file the project depends on, with path
+// relative to project directory
+func DescriptorForResource(mediaType string, path string, content []byte) v1.Descriptor {
+	return v1.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromString(string(content)),
+		Size:      int64(len(content)),
+		Annotations: map[string]string{
+			"com.docker.compose.version": api.ComposeVersion,
+			ComposeFileAnnotation:        filepath.Base(path),
+			ComposePathAnnotation:        filepath.ToSlash(path),
+		},
+	}
+}
+
+// PushManifest pushes layers and the manifest referencing them. With OCIVersionAuto, the OCI 1.1 manifest
+// is pushed first, falling back to OCI 1.0 when registry rejects it. It returns the version used and the
+// reason for it.
 func PushManifest(
 	ctx context.Context,
 	resolver *imagetools.Resolver,
 	named reference.Named,
 	layers []Pushable,
 	ociVersion api.OCIVersion,
-) error {
+) (api.OCIVersion, string, error) {
 	// prepare to push the manifest by pushing the layers
 	layerDescriptors := make([]v1.Descriptor, len(layers))
 	for i := range layers {
 		layerDescriptors[i] = layers[i].Descriptor
 		if err := resolver.Push(ctx, named, layers[i].Descriptor, layers[i].Data); err != nil {
-			return err
+			return "", "", err
 		}
 	}
 
-	if ociVersion != "" {
+	if ociVersion != "" && ociVersion != api.OCIVersionAuto {
 		// if a version was explicitly specified, use it
-		return createAndPushManifest(ctx, resolver, named, layerDescriptors, ociVersion)
+		return ociVersion, "set by user", createAndPushManifest(ctx, resolver, named, layerDescriptors, ociVersion)
 	}
 
 	// try to push in the OCI 1.1 format but fallback to OCI 1.0 on 4xx errors
 	// (other than auth) since it's most likely the result of the registry not
 	// having support
 	err := createAndPushManifest(ctx, resolver, named, layerDescriptors, api.OCIVersion1_1)
+	if err == nil {
+		return api.OCIVersion1_1, "registry accepts OCI 1.1 artifact manifests", nil
+	}
 	var pushErr pusherrors.ErrUnexpectedStatus
 	if errors.As(err, &pushErr) && isNonAuthClientError(pushErr.StatusCode) {
-		// TODO(milas): show a warning here (won't work with logrus)
-		return createAndPushManifest(ctx, resolver, named, layerDescriptors, api.OCIVersion1_0)
+		reason := fmt.Sprintf("registry rejected OCI 1.1 artifact manifest (%s), falling back to OCI 1.0", pushErr.Status)
+		return api.OCIVersion1_0, reason, createAndPushManifest(ctx, resolver, named, layerDescriptors, api.OCIVersion1_0)
 	}
-	return err
+	return "", "", err
 }
 
 func createAndPushManifest(
@@ -178,8 +211,9 @@ func generateManifest(layers []v1.Descriptor, ociCompat api.OCIVersion) ([]Pusha
 	case api.OCIVersion1_1:
 		config = v1.DescriptorEmptyJSON
 		artifactType = ComposeProjectArtifactType
-		// N.B. the descriptor has the data embedded in it
-		toPush = append(toPush, Pushable{Descriptor: config, Data: nil})
+		// N.B. the descriptor has the data embedded in it, which still must be
+		// uploaded for registries not already holding the empty blob
+		toPush = append(toPush, Pushable{Descriptor: config, Data: config.Data})
 	default:
 		return nil, fmt.Errorf("unsupported OCI version: %s", ociCompat)
 	}
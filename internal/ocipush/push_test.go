@@ -0,0 +1,140 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ocipush
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/buildx/util/resolver"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// fakeRegistry accepts blobs, and manifests unless they are OCI 1.1 artifacts and artifactStatus is set
+type fakeRegistry struct {
+	*httptest.Server
+	artifactStatus int
+	mtx            sync.Mutex
+	manifests      []v1.Manifest
+}
+
+func newFakeRegistry(t *testing.T, artifactStatus int) *fakeRegistry {
+	r := &fakeRegistry{artifactStatus: artifactStatus}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/blobs/uploads/"):
+			w.Header().Set("Location", req.URL.Path+"upload")
+			w.WriteHeader(http.StatusAccepted)
+		case req.Method == http.MethodPut && strings.Contains(req.URL.Path, "/blobs/uploads/"):
+			_, _ = io.Copy(io.Discard, req.Body)
+			w.Header().Set("Docker-Content-Digest", req.URL.Query().Get("digest"))
+			w.WriteHeader(http.StatusCreated)
+		case req.Method == http.MethodPut && strings.Contains(req.URL.Path, "/manifests/"):
+			body, err := io.ReadAll(req.Body)
+			assert.NilError(t, err)
+			var manifest v1.Manifest
+			assert.NilError(t, json.Unmarshal(body, &manifest))
+			r.mtx.Lock()
+			r.manifests = append(r.manifests, manifest)
+			r.mtx.Unlock()
+			if manifest.ArtifactType != "" && r.artifactStatus != 0 {
+				w.WriteHeader(r.artifactStatus)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(body).String())
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeRegistry) push(t *testing.T, ociVersion api.OCIVersion) (api.OCIVersion, error) {
+	host := strings.TrimPrefix(r.URL, "http://")
+	plainHTTP := true
+	res := imagetools.New(imagetools.Opt{
+		Auth: configfile.New(""),
+		RegistryConfig: map[string]resolver.RegistryConfig{
+			host: {PlainHTTP: &plainHTTP},
+		},
+	})
+	named, err := reference.ParseDockerRef(host + "/test/project:latest")
+	assert.NilError(t, err)
+	content := []byte("services: {}")
+	layers := []Pushable{{Descriptor: DescriptorForComposeFile("compose.yaml", content), Data: content}}
+	version, _, err := PushManifest(context.Background(), res, named, layers, ociVersion)
+	return version, err
+}
+
+func TestPushManifest(t *testing.T) {
+	tests := []struct {
+		name           string
+		artifactStatus int
+		ociVersion     api.OCIVersion
+		expected       api.OCIVersion
+		manifests      int
+		err            string
+	}{
+		{name: "OCI 1.1 accepted", ociVersion: api.OCIVersionAuto, expected: api.OCIVersion1_1, manifests: 1},
+		{name: "OCI 1.1 rejected", artifactStatus: http.StatusBadRequest, ociVersion: api.OCIVersionAuto, expected: api.OCIVersion1_0, manifests: 2},
+		{name: "server error", artifactStatus: http.StatusInternalServerError, ociVersion: api.OCIVersionAuto, manifests: 1, err: "500"},
+		{name: "explicit version", ociVersion: api.OCIVersion1_0, expected: api.OCIVersion1_0, manifests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newFakeRegistry(t, tt.artifactStatus)
+			version, err := registry.push(t, tt.ociVersion)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+			} else {
+				assert.NilError(t, err)
+				assert.Equal(t, version, tt.expected)
+			}
+			// no manifest is pushed besides the actual project one
+			assert.Equal(t, len(registry.manifests), tt.manifests)
+			for _, m := range registry.manifests {
+				assert.Equal(t, len(m.Layers), 1)
+			}
+		})
+	}
+}
+
+func TestIsNonAuthClientError(t *testing.T) {
+	assert.Assert(t, isNonAuthClientError(http.StatusBadRequest))
+	assert.Assert(t, isNonAuthClientError(http.StatusNotFound))
+	assert.Assert(t, !isNonAuthClientError(http.StatusUnauthorized))
+	assert.Assert(t, !isNonAuthClientError(http.StatusForbidden))
+	assert.Assert(t, !isNonAuthClientError(http.StatusInternalServerError))
+	assert.Assert(t, !isNonAuthClientError(http.StatusCreated))
+}
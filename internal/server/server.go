@@ -0,0 +1,418 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	"github.com/sirupsen/logrus"
+)
+
+// ProjectRequest describes the project an operation applies to. Project is either loaded
+// from compose files on server filesystem, or from the posted Model
+type ProjectRequest struct {
+	Name        string         `json:"name,omitempty"`
+	ConfigPaths []string       `json:"config_paths,omitempty"`
+	WorkingDir  string         `json:"working_dir,omitempty"`
+	Profiles    []string       `json:"profiles,omitempty"`
+	EnvFiles    []string       `json:"env_files,omitempty"`
+	Model       map[string]any `json:"model,omitempty"`
+}
+
+// ProjectLoader loads the project described by request, with selected services enabled
+type ProjectLoader func(ctx context.Context, request ProjectRequest, services []string) (*types.Project, error)
+
+// UpRequest is the body for POST /up
+type UpRequest struct {
+	Project       ProjectRequest `json:"project"`
+	Services      []string       `json:"services,omitempty"`
+	Build         bool           `json:"build,omitempty"`
+	ForceRecreate bool           `json:"force_recreate,omitempty"`
+	RemoveOrphans bool           `json:"remove_orphans,omitempty"`
+	Wait          bool           `json:"wait,omitempty"`
+}
+
+// DownRequest is the body for POST /down
+type DownRequest struct {
+	ProjectName   string   `json:"project_name"`
+	Services      []string `json:"services,omitempty"`
+	RemoveOrphans bool     `json:"remove_orphans,omitempty"`
+	Volumes       bool     `json:"volumes,omitempty"`
+	Images        string   `json:"images,omitempty"`
+	Timeout       *int     `json:"timeout,omitempty"`
+}
+
+// RestartRequest is the body for POST /restart
+type RestartRequest struct {
+	ProjectName string   `json:"project_name"`
+	Services    []string `json:"services,omitempty"`
+	Timeout     *int     `json:"timeout,omitempty"`
+}
+
+// ScaleRequest is the body for POST /scale
+type ScaleRequest struct {
+	Project ProjectRequest `json:"project"`
+	Scale   map[string]int `json:"scale"`
+}
+
+// WatchRequest is the body for POST /watch
+type WatchRequest struct {
+	Project  ProjectRequest `json:"project"`
+	Services []string       `json:"services,omitempty"`
+}
+
+// ConfigRequest is the body for POST /config
+type ConfigRequest struct {
+	Project ProjectRequest `json:"project"`
+	// Format is either json or yaml
+	Format string `json:"format,omitempty"`
+}
+
+// Server exposes api.Service over HTTP
+type Server struct {
+	backend api.Service
+	load    ProjectLoader
+	mux     *http.ServeMux
+}
+
+// New creates a Server for backend
+func New(backend api.Service, load ProjectLoader) *Server {
+	s := &Server{
+		backend: backend,
+		load:    load,
+		mux:     http.NewServeMux(),
+	}
+	s.handle(http.MethodPost, "/up", s.up)
+	s.handle(http.MethodPost, "/down", s.down)
+	s.handle(http.MethodGet, "/ps", s.ps)
+	s.handle(http.MethodGet, "/logs", s.logs)
+	s.handle(http.MethodGet, "/events", s.events)
+	s.handle(http.MethodPost, "/restart", s.restart)
+	s.handle(http.MethodPost, "/scale", s.scale)
+	s.handle(http.MethodPost, "/watch", s.watch)
+	s.handle(http.MethodPost, "/config", s.config)
+	return s
+}
+
+func (s *Server) handle(method string, path string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed on %s", r.Method, path))
+			return
+		}
+		logrus.Debugf("serving %s %s", r.Method, r.URL.Path)
+		handler(w, r)
+	})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Serve accepts connections on listener until context is done
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.WithoutCancel(ctx))
+	}()
+	err := srv.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// stream runs operation while forwarding progress and lifecycle events to client
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, operation func(ctx context.Context, stream *eventStream) error) {
+	stream := newEventStream(w)
+	ctx := progress.WithOutput(r.Context(), &progressOutput{stream: stream}, progress.ModeJSON)
+	ctx = api.WithLifecycleListener(ctx, stream.lifecycle)
+	stream.close(operation(ctx, stream))
+}
+
+func (s *Server) up(w http.ResponseWriter, r *http.Request) {
+	var req UpRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	project, err := s.load(r.Context(), req.Project, req.Services)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	recreate := api.RecreateDiverged
+	if req.ForceRecreate {
+		recreate = api.RecreateForce
+	}
+	var build *api.BuildOptions
+	if req.Build {
+		build = &api.BuildOptions{
+			Services: req.Services,
+		}
+	}
+	s.stream(w, r, func(ctx context.Context, _ *eventStream) error {
+		return s.backend.Up(ctx, project, api.UpOptions{
+			Create: api.CreateOptions{
+				Build:                build,
+				Services:             req.Services,
+				RemoveOrphans:        req.RemoveOrphans,
+				Recreate:             recreate,
+				RecreateDependencies: api.RecreateDiverged,
+				Inherit:              true,
+			},
+			Start: api.StartOptions{
+				Project:  project,
+				Services: req.Services,
+				Wait:     req.Wait,
+			},
+		})
+	})
+}
+
+func (s *Server) down(w http.ResponseWriter, r *http.Request) {
+	var req DownRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.ProjectName == "" {
+		writeError(w, http.StatusBadRequest, errors.New("project_name is required"))
+		return
+	}
+	s.stream(w, r, func(ctx context.Context, _ *eventStream) error {
+		return s.backend.Down(ctx, req.ProjectName, api.DownOptions{
+			RemoveOrphans: req.RemoveOrphans,
+			Volumes:       req.Volumes,
+			Images:        req.Images,
+			Timeout:       toDuration(req.Timeout),
+			Services:      req.Services,
+		})
+	})
+}
+
+func (s *Server) ps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	projectName := query.Get("project")
+	if projectName == "" {
+		writeError(w, http.StatusBadRequest, errors.New("project query parameter is required"))
+		return
+	}
+	all, _ := strconv.ParseBool(query.Get("all"))
+	containers, err := s.backend.Ps(r.Context(), projectName, api.PsOptions{
+		All:      all,
+		Services: query["service"],
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, containers)
+}
+
+func (s *Server) logs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	projectName := query.Get("project")
+	if projectName == "" {
+		writeError(w, http.StatusBadRequest, errors.New("project query parameter is required"))
+		return
+	}
+	follow, _ := strconv.ParseBool(query.Get("follow"))
+	timestamps, _ := strconv.ParseBool(query.Get("timestamps"))
+	tail := query.Get("tail")
+	if tail == "" {
+		tail = "all"
+	}
+	s.stream(w, r, func(ctx context.Context, stream *eventStream) error {
+		return s.backend.Logs(ctx, projectName, logConsumer{stream: stream}, api.LogOptions{
+			Services:   query["service"],
+			Tail:       tail,
+			Since:      query.Get("since"),
+			Until:      query.Get("until"),
+			Follow:     follow,
+			Timestamps: timestamps,
+		})
+	})
+}
+
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	projectName := query.Get("project")
+	if projectName == "" {
+		writeError(w, http.StatusBadRequest, errors.New("project query parameter is required"))
+		return
+	}
+	s.stream(w, r, func(ctx context.Context, stream *eventStream) error {
+		err := s.backend.Events(ctx, projectName, api.EventsOptions{
+			Services: query["service"],
+			Consumer: func(event api.Event) error {
+				stream.send(EventContainer, event)
+				return nil
+			},
+		})
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// client went away
+			return nil
+		}
+		return err
+	})
+}
+
+func (s *Server) restart(w http.ResponseWriter, r *http.Request) {
+	var req RestartRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.ProjectName == "" {
+		writeError(w, http.StatusBadRequest, errors.New("project_name is required"))
+		return
+	}
+	s.stream(w, r, func(ctx context.Context, _ *eventStream) error {
+		return s.backend.Restart(ctx, req.ProjectName, api.RestartOptions{
+			Services: req.Services,
+			Timeout:  toDuration(req.Timeout),
+		})
+	})
+}
+
+func (s *Server) scale(w http.ResponseWriter, r *http.Request) {
+	var req ScaleRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	var services []string
+	for name := range req.Scale {
+		services = append(services, name)
+	}
+	project, err := s.load(r.Context(), req.Project, services)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	for name, replicas := range req.Scale {
+		service, err := project.GetService(name)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		service.SetScale(replicas)
+		project.Services[name] = service
+	}
+	s.stream(w, r, func(ctx context.Context, _ *eventStream) error {
+		return s.backend.Scale(ctx, project, api.ScaleOptions{Services: services})
+	})
+}
+
+func (s *Server) watch(w http.ResponseWriter, r *http.Request) {
+	var req WatchRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	project, err := s.load(r.Context(), req.Project, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.stream(w, r, func(ctx context.Context, stream *eventStream) error {
+		err := s.backend.Watch(ctx, project, req.Services, api.WatchOptions{
+			Build: &api.BuildOptions{
+				Quiet: true,
+			},
+			LogTo: logConsumer{stream: stream},
+		})
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// client went away
+			return nil
+		}
+		return err
+	})
+}
+
+func (s *Server) config(w http.ResponseWriter, r *http.Request) {
+	var req ConfigRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	project, err := s.load(r.Context(), req.Project, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var (
+		content     []byte
+		contentType string
+	)
+	switch req.Format {
+	case "", "json":
+		content, err = project.MarshalJSON()
+		contentType = "application/json"
+	case "yaml":
+		content, err = project.MarshalYAML()
+		contentType = "application/yaml"
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported format %q", req.Format))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorMessage{Message: err.Error()})
+}
+
+func toDuration(seconds *int) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	d := time.Duration(*seconds) * time.Second
+	return &d
+}
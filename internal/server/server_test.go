@@ -0,0 +1,174 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/docker/compose/v2/pkg/progress"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func testProjectLoader(_ context.Context, request ProjectRequest, _ []string) (*types.Project, error) {
+	if request.Name == "" {
+		return nil, errors.New("no project")
+	}
+	return &types.Project{
+		Name: request.Name,
+		Services: types.Services{
+			"web": {Name: "web", Image: "nginx"},
+		},
+	}, nil
+}
+
+func TestPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	backend := mocks.NewMockService(ctrl)
+	backend.EXPECT().Ps(gomock.Any(), "test", api.PsOptions{All: true, Services: []string{"web"}}).
+		Return([]api.ContainerSummary{{ID: "123", Service: "web"}}, nil)
+
+	srv := httptest.NewServer(New(backend, testProjectLoader))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/ps?project=test&all=true&service=web")
+	assert.NilError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	var containers []api.ContainerSummary
+	assert.NilError(t, json.NewDecoder(resp.Body).Decode(&containers))
+	assert.Equal(t, len(containers), 1)
+	assert.Equal(t, containers[0].ID, "123")
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srv := httptest.NewServer(New(mocks.NewMockService(ctrl), testProjectLoader))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/up")
+	assert.NilError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, resp.StatusCode, http.StatusMethodNotAllowed)
+}
+
+func TestUpStreamsEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	backend := mocks.NewMockService(ctrl)
+	backend.EXPECT().Up(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, project *types.Project, options api.UpOptions) error {
+			assert.Equal(t, project.Name, "test")
+			return progress.Run(ctx, func(ctx context.Context) error {
+				progress.ContextWriter(ctx).Event(progress.CreatedEvent("Container test-web-1"))
+				for _, l := range api.LifecycleListeners(ctx) {
+					l(api.LifecycleEvent{Project: "test", Service: "web", Action: api.LifecycleCreated})
+				}
+				return nil
+			}, io.Discard)
+		})
+
+	srv := httptest.NewServer(New(backend, testProjectLoader))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/up", "application/json", strings.NewReader(`{"project": {"name": "test"}}`))
+	assert.NilError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NilError(t, err)
+	events := parseEvents(string(body))
+	assert.Equal(t, len(events), 3)
+	assert.Equal(t, events[0][0], EventProgress)
+	assert.Assert(t, strings.Contains(events[0][1], `"id":"Container test-web-1"`))
+	assert.Equal(t, events[1][0], EventLifecycle)
+	assert.Assert(t, strings.Contains(events[1][1], `"action":"created"`))
+	assert.Equal(t, events[2][0], EventDone)
+}
+
+func TestUpInvalidProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srv := httptest.NewServer(New(mocks.NewMockService(ctrl), testProjectLoader))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/up", "application/json", strings.NewReader(`{"project": {}}`))
+	assert.NilError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+
+	var msg ErrorMessage
+	assert.NilError(t, json.NewDecoder(resp.Body).Decode(&msg))
+	assert.Equal(t, msg.Message, "no project")
+}
+
+// parseEvents returns server-sent events as (name, data) tuples
+func parseEvents(body string) [][2]string {
+	var events [][2]string
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event [2]string
+		for _, line := range strings.Split(block, "\n") {
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event[0] = name
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				event[1] = data
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// slowResponseWriter blocks writes until unblocked, as a client not reading events
+type slowResponseWriter struct {
+	*httptest.ResponseRecorder
+	unblock chan struct{}
+}
+
+func (w slowResponseWriter) Write(b []byte) (int, error) {
+	<-w.unblock
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestLifecycleSlowClient(t *testing.T) {
+	w := slowResponseWriter{ResponseRecorder: httptest.NewRecorder(), unblock: make(chan struct{})}
+	stream := newEventStream(w)
+
+	// listener must not block while client doesn't read
+	for i := 0; i < 2*lifecycleQueueSize; i++ {
+		stream.lifecycle(api.LifecycleEvent{Project: "test", Service: "web", Action: api.LifecycleCreated})
+	}
+	close(w.unblock)
+	stream.close(nil)
+
+	events := parseEvents(w.Body.String())
+	assert.Assert(t, len(events) > 1)
+	assert.Assert(t, len(events) <= lifecycleQueueSize+2)
+	assert.Equal(t, events[0][0], EventLifecycle)
+	assert.Equal(t, events[len(events)-1][0], EventDone)
+}
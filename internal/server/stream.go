@@ -0,0 +1,213 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EventProgress is the server-sent event name for progress, data is a progress.JSONMessage
+	EventProgress = "progress"
+	// EventLifecycle is the server-sent event name for container lifecycle, data is a LifecycleMessage
+	EventLifecycle = "lifecycle"
+	// EventLog is the server-sent event name for container logs, data is a LogMessage
+	EventLog = "log"
+	// EventContainer is the server-sent event name for engine events, data is an api.Event
+	EventContainer = "event"
+	// EventError is the server-sent event name sent when operation failed, data is an ErrorMessage
+	EventError = "error"
+	// EventDone is the server-sent event name sent when operation completed successfully
+	EventDone = "done"
+)
+
+// lifecycleQueueSize is the number of lifecycle events queued for a slow client before new ones get dropped
+const lifecycleQueueSize = 256
+
+// eventStream sends server-sent events to client
+type eventStream struct {
+	mtx     sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+
+	// lifecycle events are queued, so that a slow client doesn't delay containers being processed
+	queueMtx  sync.Mutex
+	queue     chan LifecycleMessage
+	queueDone chan struct{}
+	closed    bool
+}
+
+func newEventStream(w http.ResponseWriter) *eventStream {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	s := &eventStream{
+		w:         w,
+		flusher:   flusher,
+		queue:     make(chan LifecycleMessage, lifecycleQueueSize),
+		queueDone: make(chan struct{}),
+	}
+	s.flush()
+	go func() {
+		defer close(s.queueDone)
+		for msg := range s.queue {
+			s.send(EventLifecycle, msg)
+		}
+	}()
+	return s
+}
+
+func (s *eventStream) send(event string, data any) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	s.sendRaw(event, b)
+}
+
+func (s *eventStream) sendRaw(event string, data []byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data)
+	s.flush()
+}
+
+func (s *eventStream) flush() {
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+// close sends the queued lifecycle events, then the terminal event for operation
+func (s *eventStream) close(err error) {
+	s.queueMtx.Lock()
+	s.closed = true
+	close(s.queue)
+	s.queueMtx.Unlock()
+	<-s.queueDone
+
+	if err != nil {
+		s.send(EventError, ErrorMessage{Message: err.Error()})
+		return
+	}
+	s.send(EventDone, struct{}{})
+}
+
+// progressOutput is an io.Writer to receive json progress output, and forward each line as an event
+type progressOutput struct {
+	stream *eventStream
+	mtx    sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (p *progressOutput) Write(b []byte) (int, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.buf.Write(b)
+	for {
+		line, err := p.buf.ReadBytes('\n')
+		if err != nil {
+			// incomplete line, wait for more
+			p.buf.Write(line)
+			return len(b), nil
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			p.stream.sendRaw(EventProgress, line)
+		}
+	}
+}
+
+// LifecycleMessage is the server-sent representation of an api.LifecycleEvent
+type LifecycleMessage struct {
+	Timestamp   time.Time `json:"time"`
+	Project     string    `json:"project,omitempty"`
+	Service     string    `json:"service,omitempty"`
+	Container   string    `json:"container,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Number      int       `json:"number,omitempty"`
+	Action      string    `json:"action"`
+	Reason      string    `json:"reason,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+func (s *eventStream) lifecycle(event api.LifecycleEvent) {
+	msg := LifecycleMessage{
+		Timestamp:   event.Timestamp,
+		Project:     event.Project,
+		Service:     event.Service,
+		Container:   event.Container,
+		ContainerID: event.ContainerID,
+		Number:      event.Number,
+		Action:      string(event.Action),
+		Reason:      event.Reason,
+	}
+	if event.Error != nil {
+		msg.Error = event.Error.Error()
+	}
+	s.queueMtx.Lock()
+	defer s.queueMtx.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- msg:
+	default:
+		logrus.Debugf("client is too slow, dropping %s event for container %s", msg.Action, msg.Container)
+	}
+}
+
+// LogMessage is the server-sent representation of a container log line
+type LogMessage struct {
+	Container string `json:"container"`
+	Stream    string `json:"stream"`
+	Message   string `json:"message"`
+}
+
+// logConsumer implements api.LogConsumer to forward logs as server-sent events
+type logConsumer struct {
+	stream *eventStream
+}
+
+func (l logConsumer) Log(containerName, message string) {
+	l.stream.send(EventLog, LogMessage{Container: containerName, Stream: "stdout", Message: message})
+}
+
+func (l logConsumer) Err(containerName, message string) {
+	l.stream.send(EventLog, LogMessage{Container: containerName, Stream: "stderr", Message: message})
+}
+
+func (l logConsumer) Status(container, msg string) {
+	l.stream.send(EventLog, LogMessage{Container: container, Stream: "status", Message: msg})
+}
+
+func (l logConsumer) Register(_ string) {
+}
+
+// ErrorMessage is the payload used to report an error
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
@@ -78,14 +78,28 @@ type Service interface {
 	Publish(ctx context.Context, project *types.Project, repository string, options PublishOptions) error
 	// Images executes the equivalent of a `compose images`
 	Images(ctx context.Context, projectName string, options ImagesOptions) ([]ImageSummary, error)
+	// Bundle packages project files and service images in a single archive
+	Bundle(ctx context.Context, project *types.Project, options BundleOptions) error
+	// Unbundle extracts project files from a bundle archive and loads its images
+	Unbundle(ctx context.Context, options UnbundleOptions) (BundleManifest, error)
+	// Outdated compares service images with the ones available from registry
+	Outdated(ctx context.Context, project *types.Project, options OutdatedOptions) ([]ImageUpdate, error)
+	// Lint checks project for risky or sloppy configuration
+	Lint(ctx context.Context, project *types.Project, options LintOptions) ([]LintFinding, error)
+	// Export converts project into Kubernetes manifests, and returns warnings about configuration which can't be converted
+	Export(ctx context.Context, project *types.Project, options ExportOptions) ([]string, error)
 	// MaxConcurrency defines upper limit for concurrent operations against engine API
 	MaxConcurrency(parallel int)
+	// ConfigureRegistry defines how operations against registries are retried and parallelized
+	ConfigureRegistry(options RegistryOptions)
 	// DryRunMode defines if dry run applies to the command
 	DryRunMode(ctx context.Context, dryRun bool) (context.Context, error)
 	// Watch services' development context and sync/notify/rebuild/restart on changes
 	Watch(ctx context.Context, project *types.Project, services []string, options WatchOptions) error
 	// Viz generates a graphviz graph of the project services
 	Viz(ctx context.Context, project *types.Project, options VizOptions) (string, error)
+	// Bake generates a buildx bake definition equivalent to the project build
+	Bake(ctx context.Context, project *types.Project, options BakeOptions) (string, error)
 	// Wait blocks until at least one of the services' container exits
 	Wait(ctx context.Context, projectName string, options WaitOptions) (int64, error)
 	// Scale manages numbers of container instances running per service
@@ -114,6 +128,42 @@ type VizOptions struct {
 	Indentation string
 }
 
+const (
+	// BakeFormatHCL is the HCL format for bake definition
+	BakeFormatHCL = "hcl"
+	// BakeFormatJSON is the JSON format for bake definition
+	BakeFormatJSON = "json"
+)
+
+// BuildContextSummary describes the content of a service build context, as sent to the builder
+type BuildContextSummary struct {
+	Service string `json:"service"`
+	Context string `json:"context"`
+	// Size is the total size of the files sent to the builder
+	Size int64 `json:"size"`
+	// Files is the count of files sent to the builder
+	Files        int                 `json:"files"`
+	LargestFiles []BuildContextEntry `json:"largest_files,omitempty"`
+	LargestDirs  []BuildContextEntry `json:"largest_dirs,omitempty"`
+	// Excludable lists paths sent to the builder which are commonly excluded by `.dockerignore`
+	Excludable []BuildContextEntry `json:"excludable,omitempty"`
+}
+
+// BuildContextEntry is a file or directory within a build context
+type BuildContextEntry struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+}
+
+// BakeOptions group options of the Bake API
+type BakeOptions struct {
+	// Build options, applied the same way `compose build` does
+	Build BuildOptions
+	// Format of the bake definition, either "hcl" or "json"
+	Format string
+}
+
 // WatchLogger is a reserved name to log watch events
 const WatchLogger = "#watch"
 
@@ -129,7 +179,7 @@ type BuildOptions struct {
 	Pull bool
 	// Push pushes service images
 	Push bool
-	// Progress set type of progress output ("auto", "plain", "tty")
+	// Progress set type of progress output ("auto", "plain", "tty", "json")
 	Progress string
 	// Args set build-time args
 	Args types.MappingWithEquals
@@ -147,6 +197,22 @@ type BuildOptions struct {
 	Memory int64
 	// Builder name passed in the command line
 	Builder string
+	// CacheFrom adds external cache sources to service builds, using buildx `--cache-from` syntax.
+	// An entry with a `service=NAME` attribute only applies to this service
+	CacheFrom []string
+	// CacheTo adds cache export destinations to service builds, using buildx `--cache-to` syntax.
+	// An entry with a `service=NAME` attribute only applies to this service
+	CacheTo []string
+	// CacheReplace makes CacheFrom and CacheTo replace services `cache_from` and `cache_to` rather than add to them.
+	// Only applies to services targeted by an entry, so a `service=` entry replaces cache configuration for this single service
+	CacheReplace bool
+	// Force rebuilds images even when build context and configuration have not changed
+	Force bool
+	// ContextSizeWarning is the build context size, in bytes, above which a warning is emitted. 0 disables the check
+	ContextSizeWarning int64
+	// Attestations to produce for all services, using buildx `--attest` syntax.
+	// Services can set their own with the `x-attestations` build extension
+	Attestations []string
 }
 
 // Apply mutates project according to build options
@@ -297,11 +363,91 @@ type PullOptions struct {
 	IgnoreBuildable bool
 }
 
+// RegistryOptions group settings for pull and push operations against registries
+type RegistryOptions struct {
+	// Attempts is the number of times an operation is tried before failing on a transient error
+	Attempts int
+	// MaxConcurrency limits concurrent operations against a single registry host, on top of global limit
+	MaxConcurrency int
+}
+
 // ImagesOptions group options of the Images API
 type ImagesOptions struct {
 	Services []string
 }
 
+// OutdatedOptions group options of the Outdated API
+type OutdatedOptions struct {
+	Services []string
+}
+
+const (
+	// ImageUpToDate is the status of a local image matching registry digest
+	ImageUpToDate = "up-to-date"
+	// ImageOutdated is the status of a local image with a newer image available from registry
+	ImageOutdated = "outdated"
+	// ImageMissing is the status of an image not available locally
+	ImageMissing = "missing"
+	// ImageUnknown is the status of an image which could not be checked against registry
+	ImageUnknown = "unknown"
+)
+
+// ImageUpdate describes a service image compared with registry
+type ImageUpdate struct {
+	Service      string
+	Image        string
+	LocalDigest  string
+	RemoteDigest string
+	Status       string
+	Error        string `json:",omitempty"`
+}
+
+// LintSeverity is the severity of a lint rule, using SARIF levels
+type LintSeverity string
+
+const (
+	// LintSeverityError is the severity of a configuration which is most likely a mistake or a security issue
+	LintSeverityError = LintSeverity("error")
+	// LintSeverityWarning is the severity of a risky configuration
+	LintSeverityWarning = LintSeverity("warning")
+	// LintSeverityNote is the severity of a configuration worth a review
+	LintSeverityNote = LintSeverity("note")
+	// LintSeverityOff disables a lint rule
+	LintSeverityOff = LintSeverity("off")
+)
+
+// LintRule describes a check run by the Lint API
+type LintRule struct {
+	ID          string
+	Description string
+	Severity    LintSeverity
+}
+
+// LintCheck returns messages about issues found by a lint rule in a service configuration
+type LintCheck func(project *types.Project, service types.ServiceConfig) []string
+
+// LintOptions group options of the Lint API
+type LintOptions struct {
+	Services []string
+	// Severity overrides default severity of rules, by rule ID
+	Severity map[string]LintSeverity
+}
+
+// LintFinding is a configuration issue reported by a lint rule
+type LintFinding struct {
+	Rule     string
+	Severity LintSeverity
+	Service  string `json:",omitempty"`
+	Message  string
+}
+
+// ExportOptions group options of the Export API
+type ExportOptions struct {
+	Services []string
+	// Output is the directory to write Kubernetes manifests to
+	Output string
+}
+
 // KillOptions group options of the Kill API
 type KillOptions struct {
 	// RemoveOrphans will cleanup containers that are not declared on the compose model but own the same labels
@@ -392,27 +538,77 @@ type PortOptions struct {
 // OCIVersion controls manifest generation to ensure compatibility
 // with different registries.
 //
-// Currently, this is not exposed as an option to the user – Compose uses
-// OCI 1.0 mode automatically for ECR registries based on domain and OCI 1.1
-// for all other registries.
-//
-// There are likely other popular registries that do not support the OCI 1.1
-// format, so it might make sense to expose this as a CLI flag or see if
-// there's a way to generically probe the registry for support level.
+// By default, an OCI 1.1 artifact manifest is pushed and Compose falls back
+// to OCI 1.0 if the registry rejects it. Users can also
+// set the version explicitly with `publish --oci-version`.
 type OCIVersion string
 
 const (
-	OCIVersion1_0 OCIVersion = "1.0"
-	OCIVersion1_1 OCIVersion = "1.1"
+	// OCIVersionAuto pushes an OCI 1.1 manifest, falling back to OCI 1.0 if registry rejects it
+	OCIVersionAuto OCIVersion = "auto"
+	OCIVersion1_0  OCIVersion = "1.0"
+	OCIVersion1_1  OCIVersion = "1.1"
 )
 
 // PublishOptions group options of the Publish API
 type PublishOptions struct {
 	ResolveImageDigests bool
+	// ModelFiles are local files the model was loaded from besides project compose files, like included
+	// or extended ones
+	ModelFiles []string
+	// AssumeYes publishes files looking like they contain secrets without asking for confirmation
+	AssumeYes bool
+	// Attestations to produce for all buildable services, which are then built and pushed with BuildKit
+	Attestations []string
+	// WithResources publishes ModelFiles, env files and file-based configs as additional layers. Clients
+	// which don't support those read them as compose files, so they are only published on demand
+	WithResources bool
 
 	OCIVersion OCIVersion
 }
 
+// BundleOptions group options of the Bundle API
+type BundleOptions struct {
+	// Output is the path of the bundle archive to write
+	Output string
+	// Platform to select for service images, when not set by service
+	Platform string
+	// EnvFiles are the project env files, used for interpolation, to include in bundle
+	EnvFiles []string
+	// ModelFiles are local files the model was loaded from besides project compose files, like included
+	// or extended ones
+	ModelFiles []string
+}
+
+// UnbundleOptions group options of the Unbundle API
+type UnbundleOptions struct {
+	// Input is the path of the bundle archive to read
+	Input string
+	// Directory to extract project files into
+	Directory string
+}
+
+// BundleManifest describes the content of a bundle archive
+type BundleManifest struct {
+	Version  int      `json:"version"`
+	Name     string   `json:"name"`
+	Profiles []string `json:"profiles,omitempty"`
+	// ComposeFiles and EnvFiles are relative to project directory
+	ComposeFiles []string `json:"composeFiles"`
+	EnvFiles     []string `json:"envFiles,omitempty"`
+	// ImageDigests is a compose override pinning service images to their digest, relative to project directory
+	ImageDigests string        `json:"imageDigests,omitempty"`
+	Images       []BundleImage `json:"images"`
+}
+
+// BundleImage is a service image saved in bundle
+type BundleImage struct {
+	Service  string `json:"service"`
+	Image    string `json:"image"`
+	ID       string `json:"id"`
+	Platform string `json:"platform,omitempty"`
+}
+
 func (e Event) String() string {
 	t := e.Timestamp.Format("2006-01-02 15:04:05.000000")
 	var attr []string
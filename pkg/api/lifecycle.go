@@ -0,0 +1,109 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LifecycleAction is the action a LifecycleEvent reports about
+type LifecycleAction string
+
+const (
+	// LifecycleCreating is emitted when container creation begins
+	LifecycleCreating LifecycleAction = "creating"
+	// LifecycleCreated is emitted once container has been created
+	LifecycleCreated LifecycleAction = "created"
+	// LifecycleRecreating is emitted when container is about to be replaced by a new one
+	LifecycleRecreating LifecycleAction = "recreating"
+	// LifecycleRecreated is emitted once container has been replaced
+	LifecycleRecreated LifecycleAction = "recreated"
+	// LifecycleStarting is emitted when container is being started
+	LifecycleStarting LifecycleAction = "starting"
+	// LifecycleStarted is emitted once container has been started
+	LifecycleStarted LifecycleAction = "started"
+	// LifecycleRunning is emitted for a container which is already running and up-to-date
+	LifecycleRunning LifecycleAction = "running"
+	// LifecycleRestarting is emitted when container is being restarted
+	LifecycleRestarting LifecycleAction = "restarting"
+	// LifecycleRestarted is emitted once container has been restarted
+	LifecycleRestarted LifecycleAction = "restarted"
+	// LifecycleWaiting is emitted when a dependent service waits for container to satisfy a condition
+	LifecycleWaiting LifecycleAction = "waiting"
+	// LifecycleHealthy is emitted once container is healthy
+	LifecycleHealthy LifecycleAction = "healthy"
+	// LifecycleExited is emitted once container completed successfully
+	LifecycleExited LifecycleAction = "exited"
+	// LifecycleStopping is emitted when container is being stopped
+	LifecycleStopping LifecycleAction = "stopping"
+	// LifecycleStopped is emitted once container has been stopped
+	LifecycleStopped LifecycleAction = "stopped"
+	// LifecycleKilling is emitted when container is being killed
+	LifecycleKilling LifecycleAction = "killing"
+	// LifecycleKilled is emitted once container has been killed
+	LifecycleKilled LifecycleAction = "killed"
+	// LifecycleRemoving is emitted when container is being removed
+	LifecycleRemoving LifecycleAction = "removing"
+	// LifecycleRemoved is emitted once container has been removed
+	LifecycleRemoved LifecycleAction = "removed"
+	// LifecycleSkipped is emitted when waiting for an optional dependency is abandoned. Reason is set
+	LifecycleSkipped LifecycleAction = "skipped"
+	// LifecycleFailed is emitted when an action failed. Error is set
+	LifecycleFailed LifecycleAction = "failed"
+)
+
+// LifecycleEvent notifies a change in the lifecycle of a service container
+type LifecycleEvent struct {
+	Timestamp time.Time
+	Project   string
+	Service   string
+	// Container is the container name
+	Container   string
+	ContainerID string
+	Number      int
+	Action      LifecycleAction
+	Reason      string
+	Error       error
+}
+
+// LifecycleListener is a callback to process LifecycleEvent
+type LifecycleListener func(event LifecycleEvent)
+
+type lifecycleListenerKey struct{}
+
+// WithLifecycleListener registers a listener to be notified about container lifecycle events
+// by the Service methods invoked with the returned context. A listener is never invoked concurrently,
+// even when containers are processed concurrently, so it should return quickly as it delays the
+// container it is notified about
+func WithLifecycleListener(ctx context.Context, listener LifecycleListener) context.Context {
+	var mtx sync.Mutex
+	listeners := append(LifecycleListeners(ctx), func(event LifecycleEvent) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		listener(event)
+	})
+	return context.WithValue(ctx, lifecycleListenerKey{}, listeners)
+}
+
+// LifecycleListeners returns the listeners registered on context
+func LifecycleListeners(ctx context.Context) []LifecycleListener {
+	listeners, _ := ctx.Value(lifecycleListenerKey{}).([]LifecycleListener)
+	// copy, so that sibling contexts don't share backing array
+	return append([]LifecycleListener(nil), listeners...)
+}
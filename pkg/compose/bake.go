@@ -0,0 +1,337 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/containerd/containerd/platforms"
+	"github.com/docker/buildx/build"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/docker/builder/remotecontext/urlutil"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// bakeConfig is the bake definition, see https://docs.docker.com/build/bake/reference/
+type bakeConfig struct {
+	Groups  map[string]bakeGroup  `json:"group"`
+	Targets map[string]bakeTarget `json:"target"`
+}
+
+type bakeGroup struct {
+	Targets []string `json:"targets"`
+}
+
+type bakeTarget struct {
+	Context          string            `json:"context,omitempty"`
+	Dockerfile       string            `json:"dockerfile,omitempty"`
+	DockerfileInline string            `json:"dockerfile-inline,omitempty"`
+	Contexts         map[string]string `json:"contexts,omitempty"`
+	Args             map[string]string `json:"args,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Tags             []string          `json:"tags,omitempty"`
+	Target           string            `json:"target,omitempty"`
+	Platforms        []string          `json:"platforms,omitempty"`
+	CacheFrom        []string          `json:"cache-from,omitempty"`
+	CacheTo          []string          `json:"cache-to,omitempty"`
+	Secrets          []string          `json:"secret,omitempty"`
+	SSH              []string          `json:"ssh,omitempty"`
+	Outputs          []string          `json:"output,omitempty"`
+	Attest           []string          `json:"attest,omitempty"`
+	Pull             bool              `json:"pull,omitempty"`
+	NoCache          bool              `json:"no-cache,omitempty"`
+	Ulimits          []string          `json:"ulimits,omitempty"`
+	ShmSize          string            `json:"shm-size,omitempty"`
+}
+
+func (s *composeService) Bake(_ context.Context, project *types.Project, options api.BakeOptions) (string, error) {
+	err := options.Build.Apply(project)
+	if err != nil {
+		return "", err
+	}
+
+	// without explicit selection, all buildable services are exported so profile groups are complete
+	candidates := project.AllServices()
+	if len(options.Build.Services) > 0 {
+		candidates = types.Services{}
+		var policy types.DependencyOption = types.IgnoreDependencies
+		if options.Build.Deps {
+			policy = types.IncludeDependencies
+		}
+		err = project.ForEachService(options.Build.Services, func(name string, service *types.ServiceConfig) error {
+			candidates[name] = *service
+			return nil
+		}, policy)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	services := map[string]serviceToBuild{}
+	for name, service := range candidates {
+		if service.Build == nil {
+			continue
+		}
+		services[name] = serviceToBuild{name: name, service: service}
+	}
+	if _, err := s.labelBuildHashes(project, services, options.Build); err != nil {
+		return "", err
+	}
+	buildOptions, err := s.servicesBuildOptions(project, services, options.Build)
+	if err != nil {
+		return "", err
+	}
+
+	config := bakeConfig{
+		Groups:  map[string]bakeGroup{},
+		Targets: map[string]bakeTarget{},
+	}
+	for name, opts := range buildOptions {
+		service := services[name].service
+		target, err := toBakeTarget(project, service, opts, options.Build)
+		if err != nil {
+			return "", err
+		}
+		config.Targets[name] = target
+
+		if _, enabled := project.Services[name]; enabled {
+			addToBakeGroup(config.Groups, "default", name)
+		}
+		for _, profile := range service.Profiles {
+			addToBakeGroup(config.Groups, profile, name)
+		}
+	}
+	for name, group := range config.Groups {
+		sort.Strings(group.Targets)
+		config.Groups[name] = group
+	}
+
+	switch options.Format {
+	case api.BakeFormatJSON:
+		b, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(b) + "\n", nil
+	case api.BakeFormatHCL, "":
+		return config.hcl()
+	default:
+		return "", fmt.Errorf("unsupported bake format %q", options.Format)
+	}
+}
+
+func addToBakeGroup(groups map[string]bakeGroup, group string, target string) {
+	g := groups[group]
+	g.Targets = append(g.Targets, target)
+	groups[group] = g
+}
+
+// toBakeTarget converts build options computed for service into the equivalent bake target
+func toBakeTarget(project *types.Project, service types.ServiceConfig, opts build.Options, options api.BuildOptions) (bakeTarget, error) {
+	target := bakeTarget{
+		Context:          opts.Inputs.ContextPath,
+		Dockerfile:       bakeDockerfile(opts.Inputs.ContextPath, opts.Inputs.DockerfilePath),
+		DockerfileInline: opts.Inputs.DockerfileInline,
+		Args:             opts.BuildArgs,
+		Labels:           opts.Labels,
+		Tags:             opts.Tags,
+		Target:           opts.Target,
+		Pull:             opts.Pull,
+		NoCache:          opts.NoCache,
+	}
+	if len(opts.Inputs.NamedContexts) > 0 {
+		target.Contexts = map[string]string{}
+		for name, c := range opts.Inputs.NamedContexts {
+			target.Contexts[name] = c.Path
+		}
+	}
+	for _, p := range opts.Platforms {
+		target.Platforms = append(target.Platforms, platforms.Format(p))
+	}
+	for _, c := range opts.CacheFrom {
+		target.CacheFrom = append(target.CacheFrom, bakeEntry(c.Type, c.Attrs))
+	}
+	for _, c := range opts.CacheTo {
+		target.CacheTo = append(target.CacheTo, bakeEntry(c.Type, c.Attrs))
+	}
+	for _, e := range opts.Exports {
+		target.Outputs = append(target.Outputs, bakeEntry(e.Type, e.Attrs))
+	}
+	for _, typ := range maps.Keys(opts.Attests) {
+		if spec := opts.Attests[typ]; spec != nil {
+			target.Attest = append(target.Attest, *spec)
+		} else {
+			target.Attest = append(target.Attest, csvJoin("type="+typ, "disabled=true"))
+		}
+	}
+	sort.Strings(target.Attest)
+	if opts.ShmSize > 0 {
+		target.ShmSize = strconv.FormatInt(int64(opts.ShmSize), 10)
+	}
+	if opts.Ulimits != nil {
+		for _, u := range opts.Ulimits.GetList() {
+			target.Ulimits = append(target.Ulimits, u.String())
+		}
+	}
+
+	sources, err := buildSecretSources(project, service)
+	if err != nil {
+		return bakeTarget{}, err
+	}
+	for _, source := range sources {
+		if source.FilePath != "" {
+			target.Secrets = append(target.Secrets, csvJoin("id="+source.ID, "src="+source.FilePath))
+		} else {
+			target.Secrets = append(target.Secrets, csvJoin("id="+source.ID, "env="+source.Env))
+		}
+	}
+	for _, key := range append(service.Build.SSH, options.SSHs...) {
+		if key.Path == "" {
+			target.SSH = append(target.SSH, key.ID)
+		} else {
+			target.SSH = append(target.SSH, key.ID+"="+key.Path)
+		}
+	}
+
+	if opts.NetworkMode != "" {
+		logrus.Warnf("service %q: build network is not supported by bake and will be ignored", service.Name)
+	}
+	if len(opts.ExtraHosts) > 0 {
+		logrus.Warnf("service %q: build extra_hosts are not supported by bake and will be ignored", service.Name)
+	}
+	if len(opts.Allow) > 0 {
+		logrus.Warnf("service %q: build entitlements must be granted with bake --allow", service.Name)
+	}
+	return target, nil
+}
+
+// bakeDockerfile returns dockerfile path relative to context, as bake resolves it
+func bakeDockerfile(context string, dockerfile string) string {
+	if dockerfile == "" || urlutil.IsGitURL(context) || filepath.IsAbs(dockerfile) {
+		return dockerfile
+	}
+	rel, err := filepath.Rel(context, dockerfile)
+	if err != nil {
+		return dockerfile
+	}
+	return rel
+}
+
+// bakeEntry formats a typed entry as a CSV string, as parsed by buildx flags
+func bakeEntry(typ string, attrs map[string]string) string {
+	fields := []string{"type=" + typ}
+	keys := maps.Keys(attrs)
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, k+"="+attrs[k])
+	}
+	return csvJoin(fields...)
+}
+
+func csvJoin(fields ...string) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	_ = w.Write(fields)
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// hcl renders bake definition using HCL syntax
+func (c bakeConfig) hcl() (string, error) {
+	var b strings.Builder
+	groups := maps.Keys(c.Groups)
+	sort.Strings(groups)
+	for _, name := range groups {
+		targets, err := hclValue(reflect.ValueOf(c.Groups[name].Targets))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "group %s {\n", hclString(name))
+		fmt.Fprintf(&b, "  targets = %s\n", targets)
+		b.WriteString("}\n\n")
+	}
+	targets := maps.Keys(c.Targets)
+	sort.Strings(targets)
+	for i, name := range targets {
+		fmt.Fprintf(&b, "target %s {\n", hclString(name))
+		v := reflect.ValueOf(c.Targets[name])
+		for f := 0; f < v.NumField(); f++ {
+			field := v.Field(f)
+			if field.IsZero() {
+				continue
+			}
+			attr, _, _ := strings.Cut(v.Type().Field(f).Tag.Get("json"), ",")
+			value, err := hclValue(field)
+			if err != nil {
+				return "", fmt.Errorf("target %s: %s: %w", name, attr, err)
+			}
+			fmt.Fprintf(&b, "  %s = %s\n", attr, value)
+		}
+		b.WriteString("}\n")
+		if i < len(targets)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
+
+func hclValue(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return hclString(v.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = hclString(v.Index(i).String())
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "    %s = %s\n", hclString(k), hclString(v.MapIndex(reflect.ValueOf(k)).String()))
+		}
+		b.WriteString("  }")
+		return b.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported bake attribute type %s", v.Kind())
+	}
+}
+
+// hclString quotes a string as an HCL literal, escaping template sequences
+func hclString(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+	return strconv.Quote(s)
+}
@@ -0,0 +1,143 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	compose "github.com/docker/compose/v2/pkg/api"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func bakeTestProject(t *testing.T) *types.Project {
+	dir := t.TempDir()
+	for _, context := range []string{"base", "app/build", "tools"} {
+		assert.NilError(t, os.MkdirAll(filepath.Join(dir, context), 0o700))
+	}
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "app", "build", "Dockerfile"), []byte("FROM test-base\n"), 0o600))
+	return &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"base": {Name: "base", Build: &types.BuildConfig{Context: filepath.Join(dir, "base")}},
+			"app": {
+				Name:  "app",
+				Image: "user/app",
+				Build: &types.BuildConfig{
+					Context:    filepath.Join(dir, "app"),
+					Dockerfile: "build/Dockerfile",
+					Args:       types.NewMappingWithEquals([]string{"VERSION=1.0"}),
+					Target:     "prod",
+					Platforms:  []string{"linux/amd64"},
+					CacheFrom:  []string{"type=registry,ref=user/app:cache"},
+					Secrets:    []types.ServiceSecretConfig{{Source: "token"}},
+					ShmSize:    types.UnitBytes(64 * 1024 * 1024),
+					AdditionalContexts: types.Mapping{
+						"base": "service:base",
+					},
+				},
+				DependsOn: types.DependsOnConfig{
+					"base": {Condition: types.ServiceConditionStarted},
+				},
+			},
+			"db": {Name: "db", Image: "postgres"},
+		},
+		DisabledServices: types.Services{
+			"tools": {Name: "tools", Profiles: []string{"debug"}, Build: &types.BuildConfig{Context: filepath.Join(dir, "tools")}},
+		},
+		Secrets: types.Secrets{
+			"token": {Name: "token", Environment: "TOKEN"},
+		},
+	}
+}
+
+func TestBakeJSON(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	api.EXPECT().DaemonHost().Return("unix:///var/run/docker.sock").AnyTimes()
+	tested := composeService{dockerCli: cli}
+
+	project := bakeTestProject(t)
+	out, err := tested.Bake(context.Background(), project, compose.BakeOptions{Format: compose.BakeFormatJSON})
+	assert.NilError(t, err)
+
+	var config bakeConfig
+	assert.NilError(t, json.Unmarshal([]byte(out), &config))
+	assert.DeepEqual(t, config.Groups, map[string]bakeGroup{
+		"default": {Targets: []string{"app", "base"}},
+		"debug":   {Targets: []string{"tools"}},
+	})
+
+	app := config.Targets["app"]
+	assert.Equal(t, app.Context, project.Services["app"].Build.Context)
+	assert.Equal(t, app.Dockerfile, filepath.Join(app.Context, "build", "Dockerfile"))
+	assert.Equal(t, app.Target, "prod")
+	assert.DeepEqual(t, app.Args, map[string]string{"VERSION": "1.0"})
+	assert.DeepEqual(t, app.Tags, []string{"user/app"})
+	assert.DeepEqual(t, app.Platforms, []string{"linux/amd64"})
+	assert.DeepEqual(t, app.CacheFrom, []string{"type=registry,ref=user/app:cache"})
+	assert.DeepEqual(t, app.Secrets, []string{"id=token,env=TOKEN"})
+	assert.DeepEqual(t, app.Contexts, map[string]string{"base": "target:base", "test-base": "target:base"})
+	assert.Equal(t, app.ShmSize, "67108864")
+	assert.DeepEqual(t, app.Outputs, []string{"type=docker,load=true,push=false"})
+	assert.Equal(t, app.Labels[compose.ServiceLabel], "app")
+	assert.Assert(t, app.Labels[compose.ImageBuildHashLabel] != "")
+	assert.DeepEqual(t, config.Targets["base"].Tags, []string{"test-base"})
+}
+
+func TestBakeHCL(t *testing.T) {
+	config := bakeConfig{
+		Groups: map[string]bakeGroup{"default": {Targets: []string{"app"}}},
+		Targets: map[string]bakeTarget{
+			"app": {
+				Context: ".",
+				Args:    map[string]string{"GREETING": "${USER}"},
+				Tags:    []string{"user/app"},
+				Pull:    true,
+			},
+		},
+	}
+	hcl, err := config.hcl()
+	assert.NilError(t, err)
+	assert.Equal(t, hcl, `group "default" {
+  targets = ["app"]
+}
+
+target "app" {
+  context = "."
+  args = {
+    "GREETING" = "$${USER}"
+  }
+  tags = ["user/app"]
+  pull = true
+}
+`)
+}
+
+func TestBakeHCLUnsupportedType(t *testing.T) {
+	_, err := hclValue(reflect.ValueOf(42))
+	assert.ErrorContains(t, err, "unsupported bake attribute type int")
+}
@@ -17,16 +17,24 @@
 package compose
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/moby/buildkit/util/progress/progressui"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/containerd/console"
 	"github.com/containerd/containerd/platforms"
+	"github.com/distribution/reference"
 	"github.com/docker/buildx/build"
 	"github.com/docker/buildx/builder"
 	"github.com/docker/buildx/controller/pb"
@@ -40,8 +48,10 @@ import (
 	"github.com/docker/compose/v2/pkg/progress"
 	"github.com/docker/compose/v2/pkg/utils"
 	"github.com/docker/docker/builder/remotecontext/urlutil"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-units"
 	bclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
 	"github.com/moby/buildkit/session/secrets/secretsprovider"
@@ -49,6 +59,7 @@ import (
 	"github.com/moby/buildkit/util/entitlements"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 
 	// required to get default driver registered
 	_ "github.com/docker/buildx/driver/docker"
@@ -68,6 +79,8 @@ func (s *composeService) Build(ctx context.Context, project *types.Project, opti
 type serviceToBuild struct {
 	name    string
 	service types.ServiceConfig
+	// context summarizes build context, when already analyzed
+	context *api.BuildContextSummary
 }
 
 //nolint:gocyclo
@@ -100,6 +113,22 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		return imageIDs, err
 	}
 
+	if buildkitEnabled {
+		upToDate, err := s.checkBuildHashes(ctx, project, serviceToBeBuild, options)
+		if err != nil {
+			return nil, err
+		}
+		for name, id := range upToDate {
+			imageIDs[api.GetImageNameOrDefault(serviceToBeBuild[name].service, project.Name)] = id
+			delete(serviceToBeBuild, name)
+			progress.ContextWriter(ctx).Event(progress.NewEvent("Service "+name, progress.Done, "Up to date"))
+		}
+		if len(serviceToBeBuild) == 0 {
+			return imageIDs, nil
+		}
+	}
+	warnLargeBuildContexts(serviceToBeBuild, options.ContextSizeWarning)
+
 	// Initialize buildkit nodes
 	var (
 		b     *builder.Builder
@@ -127,10 +156,26 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		progressCtx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		if _, mode, ok := progress.ContextOutput(ctx); ok {
+			// caller redirected progress, typically to an API client, build progress follows
+			options.Progress = mode
+		}
 		if options.Quiet {
 			options.Progress = progress.ModeQuiet
 		}
-		w, err = xprogress.NewPrinter(progressCtx, os.Stdout, progressui.DisplayMode(options.Progress),
+		var out console.File = os.Stdout
+		displayMode := progressui.DisplayMode(options.Progress)
+		if options.Progress == progress.ModeJSON {
+			// buildkit has its own json representation for solve status, sent to the
+			// same stream as compose json progress events, wrapped as buildkit messages
+			displayMode = progressui.RawJSONMode
+			jsonOut := s.stdinfo()
+			if o, _, ok := progress.ContextOutput(ctx); ok {
+				jsonOut = o
+			}
+			out = writerFile{progress.NewBuildKitJSONWriter(jsonOut, s.dryRun)}
+		}
+		w, err = xprogress.NewPrinter(progressCtx, out, displayMode,
 			xprogress.WithDesc(
 				fmt.Sprintf("building with %q instance using %s driver", b.Name, b.Driver),
 				fmt.Sprintf("%s:%s", b.Driver, b.Name),
@@ -152,15 +197,14 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		}
 		return -1
 	}
-	err = InDependencyOrder(ctx, project, func(ctx context.Context, name string) error {
-		serviceToBuild, ok := serviceToBeBuild[name]
-		if !ok {
-			return nil
-		}
-		service := serviceToBuild.service
 
-		if !buildkitEnabled {
-			id, err := s.doBuildClassic(ctx, project, service, options)
+	if !buildkitEnabled {
+		err = InDependencyOrder(ctx, project, func(ctx context.Context, name string) error {
+			serviceToBuild, ok := serviceToBeBuild[name]
+			if !ok {
+				return nil
+			}
+			id, err := s.doBuildClassic(ctx, project, serviceToBuild.service, options)
 			if err != nil {
 				return err
 			}
@@ -170,37 +214,46 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 				return s.push(ctx, project, api.PushOptions{})
 			}
 			return nil
+		}, func(traversal *graphTraversal) {
+			traversal.maxConcurrency = s.maxConcurrency
+		})
+		if err != nil {
+			return nil, err
 		}
-
+	} else {
 		if options.Memory != 0 {
 			fmt.Fprintln(s.stderr(), "WARNING: --memory is not supported by BuildKit and will be ignored")
 		}
 
-		buildOptions, err := s.toBuildOptions(project, service, options)
+		// all services are built within a single build session, so that BuildKit can
+		// dedupe shared stages and context transfers, the same way bake does
+		buildOptions, err := s.servicesBuildOptions(project, serviceToBeBuild, options)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		digest, err := s.doBuildBuildkit(ctx, name, buildOptions, w, nodes)
-		if err != nil {
-			return err
+		for name, opts := range buildOptions {
+			if hasAttestations(opts.Attests) {
+				progress.ContextWriter(ctx).Event(progress.Event{
+					ID:         "Service " + name,
+					Status:     progress.Done,
+					Text:       "Attestations",
+					StatusText: attestationsSummary(opts.Attests),
+				})
+			}
 		}
-		builtDigests[getServiceIndex(name)] = digest
 
-		return nil
-	}, func(traversal *graphTraversal) {
-		traversal.maxConcurrency = s.maxConcurrency
-	})
+		digests, err := s.doBuildBuildkit(ctx, buildOptions, w, nodes)
 
-	// enforce all build event get consumed
-	if buildkitEnabled {
+		// enforce all build event get consumed
 		if errw := w.Wait(); errw != nil {
 			return nil, errw
 		}
-	}
-
-	if err != nil {
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+		for name, digest := range digests {
+			builtDigests[getServiceIndex(name)] = digest
+		}
 	}
 
 	for i, imageDigest := range builtDigests {
@@ -212,6 +265,119 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 	return imageIDs, err
 }
 
+// servicesBuildOptions computes build options for services built within a single build session,
+// declaring the named contexts they share. Both build and bake rely on it so they run the same builds
+func (s *composeService) servicesBuildOptions(project *types.Project, services map[string]serviceToBuild, options api.BuildOptions) (map[string]build.Options, error) {
+	buildOptions := map[string]build.Options{}
+	for name, toBuild := range services {
+		opts, err := s.toBuildOptions(project, toBuild.service, options)
+		if err != nil {
+			return nil, err
+		}
+		buildOptions[name] = opts
+	}
+	addDependencyContexts(project, buildOptions)
+	resolveServiceContexts(project, buildOptions)
+	return buildOptions, nil
+}
+
+// buildCanBeSkipped tells if service build can be skipped when an image was built from the same inputs.
+// Global cache and attestations options are not part of the build fingerprint, so they disable skipping
+func buildCanBeSkipped(service types.ServiceConfig, options api.BuildOptions) bool {
+	if options.Force || options.Push || options.Pull || options.NoCache || service.Build.NoCache || service.Build.Pull {
+		return false
+	}
+	return len(options.CacheFrom) == 0 && len(options.CacheTo) == 0 && len(options.Attestations) == 0
+}
+
+// labelBuildHashes labels services build with a fingerprint of their build inputs, and returns those by service.
+// Only builds which can be skipped are fingerprinted
+func (s *composeService) labelBuildHashes(project *types.Project, services map[string]serviceToBuild, options api.BuildOptions) (map[string]string, error) {
+	hashes := map[string]string{}
+	for name, toBuild := range services {
+		service := toBuild.service
+		if !buildCanBeSkipped(service, options) {
+			continue
+		}
+		// build context is analyzed while walked to compute hash, so it doesn't get walked again
+		// to warn about its size
+		var analyzer *contextAnalyzer
+		var visit func(path string, rel string, info fs.FileInfo) error
+		if options.ContextSizeWarning > 0 && !isRemoteContext(service.Build.Context) {
+			a, err := newContextAnalyzer(service)
+			if err != nil {
+				return nil, err
+			}
+			analyzer, visit = a, a.visit
+		}
+		hash, err := buildHash(service, resolveAndMergeBuildArgs(s.dockerCli, project, service, options), visit)
+		if err != nil {
+			return nil, err
+		}
+		if hash == "" {
+			continue
+		}
+		buildConfig := *service.Build
+		buildConfig.Labels = types.Labels{}
+		for k, v := range service.Build.Labels {
+			buildConfig.Labels[k] = v
+		}
+		buildConfig.Labels[api.ImageBuildHashLabel] = hash
+		service.Build = &buildConfig
+		toBuild = serviceToBuild{name: name, service: service}
+		if analyzer != nil {
+			summary := analyzer.result(0)
+			toBuild.context = &summary
+		}
+		services[name] = toBuild
+		hashes[name] = hash
+	}
+	return hashes, nil
+}
+
+// checkBuildHashes labels services build with a fingerprint of their build inputs, and returns the ID of
+// existing images built from the same fingerprint, for which build can be skipped
+func (s *composeService) checkBuildHashes(ctx context.Context, project *types.Project, services map[string]serviceToBuild, options api.BuildOptions) (map[string]string, error) {
+	hashes, err := s.labelBuildHashes(project, services, options)
+	if err != nil {
+		return nil, err
+	}
+	upToDate := map[string]string{}
+	for name, hash := range hashes {
+		service := services[name].service
+		inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, api.GetImageNameOrDefault(service, project.Name))
+		if errdefs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if inspect.Config != nil && inspect.Config.Labels[api.ImageBuildHashLabel] == hash {
+			upToDate[name] = inspect.ID
+		}
+	}
+
+	// a service using another one as build context must be rebuilt when the latter is
+	for changed := true; changed; {
+		changed = false
+		for name := range upToDate {
+			for _, c := range services[name].service.Build.AdditionalContexts {
+				dependency, ok := strings.CutPrefix(c, serviceContextPrefix)
+				if !ok {
+					continue
+				}
+				if _, built := services[dependency]; built {
+					if _, skipped := upToDate[dependency]; !skipped {
+						delete(upToDate, name)
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return upToDate, nil
+}
+
 func (s *composeService) ensureImagesExists(ctx context.Context, project *types.Project, buildOpts *api.BuildOptions, quietPull bool) error {
 	for name, service := range project.Services {
 		if service.Image == "" && service.Build == nil {
@@ -356,11 +522,11 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 		return build.Options{}, err
 	}
 
-	cacheFrom, err := buildflags.ParseCacheEntry(service.Build.CacheFrom)
+	cacheFrom, err := serviceCacheEntries(service, service.Build.CacheFrom, options.CacheFrom, options.CacheReplace)
 	if err != nil {
 		return build.Options{}, err
 	}
-	cacheTo, err := buildflags.ParseCacheEntry(service.Build.CacheTo)
+	cacheTo, err := serviceCacheEntries(service, service.Build.CacheTo, options.CacheTo, options.CacheReplace)
 	if err != nil {
 		return build.Options{}, err
 	}
@@ -399,6 +565,11 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 
 	imageLabels := getImageBuildLabels(project, service)
 
+	attests, err := serviceAttestations(service, options.Attestations)
+	if err != nil {
+		return build.Options{}, err
+	}
+
 	push := options.Push && service.Image != ""
 	exports := []bclient.ExportEntry{{
 		Type: "docker",
@@ -407,7 +578,8 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 			"push": fmt.Sprint(push),
 		},
 	}}
-	if len(service.Build.Platforms) > 1 {
+	// docker exporter would strip attestations from pushed image
+	if len(service.Build.Platforms) > 1 || push && hasAttestations(attests) {
 		exports = []bclient.ExportEntry{{
 			Type: "image",
 			Attrs: map[string]string{
@@ -441,12 +613,84 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 		NetworkMode:  service.Build.Network,
 		ExtraHosts:   service.Build.ExtraHosts.AsList(":"),
 		Ulimits:      toUlimitOpt(service.Build.Ulimits),
+		ShmSize:      cliopts.MemBytes(service.Build.ShmSize),
 		Session:      sessionConfig,
 		Allow:        allow,
 		SourcePolicy: sp,
+		Attests:      attests,
 	}, nil
 }
 
+// attestationsExtension is the build extension for services to declare attestations, as a mapping
+// of attestation type to parameters, e.g. `sbom: true` or `provenance: mode=max`
+const attestationsExtension = "x-attestations"
+
+// serviceAttestations merges attestations set for all services with the ones declared by service,
+// which take precedence for the same attestation type
+func serviceAttestations(service types.ServiceConfig, global []string) (map[string]*string, error) {
+	specs := append([]string{}, global...)
+	if x, ok := service.Build.Extensions[attestationsExtension]; ok {
+		declared, ok := x.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("service %q: %s must be a mapping of attestation type to parameters", service.Name, attestationsExtension)
+		}
+		names := maps.Keys(declared)
+		sort.Strings(names)
+		for _, typ := range names {
+			value := declared[typ]
+			if value == nil {
+				value = true
+			}
+			specs = append(specs, buildflags.CanonicalizeAttest(typ, fmt.Sprint(value)))
+		}
+	}
+
+	byType := map[string]*pb.Attest{}
+	var order []string
+	for _, spec := range specs {
+		attest, err := buildflags.ParseAttest(spec)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: invalid attestation %q: %w", service.Name, spec, err)
+		}
+		if attest == nil {
+			continue
+		}
+		if _, ok := byType[attest.Type]; !ok {
+			order = append(order, attest.Type)
+		}
+		byType[attest.Type] = attest
+	}
+	if len(order) == 0 {
+		return nil, nil
+	}
+	attests := make([]*pb.Attest, 0, len(order))
+	for _, typ := range order {
+		attests = append(attests, byType[typ])
+	}
+	return pb.CreateAttestations(attests), nil
+}
+
+func hasAttestations(attests map[string]*string) bool {
+	for _, v := range attests {
+		if v != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// attestationsSummary describes enabled attestations for progress output
+func attestationsSummary(attests map[string]*string) string {
+	var enabled []string
+	for typ, v := range attests {
+		if v != nil {
+			enabled = append(enabled, typ)
+		}
+	}
+	sort.Strings(enabled)
+	return strings.Join(enabled, ", ")
+}
+
 func toUlimitOpt(ulimits map[string]*types.UlimitsConfig) *cliopts.UlimitOpt {
 	ref := map[string]*units.Ulimit{}
 	for _, limit := range toUlimits(ulimits) {
@@ -459,6 +703,63 @@ func toUlimitOpt(ulimits map[string]*types.UlimitsConfig) *cliopts.UlimitOpt {
 	return cliopts.NewUlimitOpt(&ref)
 }
 
+// serviceCacheEntries merges cache entries set by compose file for service with the ones passed by build options.
+// Override entries can use a `service=NAME` attribute to only apply to a single service
+func serviceCacheEntries(service types.ServiceConfig, entries []string, overrides []string, replace bool) ([]*pb.CacheOptionsEntry, error) {
+	var specs []string
+	for _, override := range overrides {
+		spec, target, err := cacheEntryService(override)
+		if err != nil {
+			return nil, err
+		}
+		if target == "" || target == service.Name {
+			specs = append(specs, spec)
+		}
+	}
+	// replace only applies to services targeted by an override, so that `service=` entries can
+	// replace cache configuration for a single service
+	if !replace || len(specs) == 0 {
+		specs = append(append([]string{}, entries...), specs...)
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	return buildflags.ParseCacheEntry(specs)
+}
+
+// cacheEntryService extracts the `service` attribute from a cache entry
+func cacheEntryService(entry string) (string, string, error) {
+	if !strings.Contains(entry, "=") {
+		// short syntax for a registry reference
+		return entry, "", nil
+	}
+	fields, err := csv.NewReader(strings.NewReader(entry)).Read()
+	if err != nil {
+		return "", "", fmt.Errorf("invalid cache entry %q: %w", entry, err)
+	}
+	var (
+		service string
+		kept    []string
+	)
+	for _, field := range fields {
+		if name, ok := strings.CutPrefix(field, "service="); ok {
+			service = name
+			continue
+		}
+		kept = append(kept, field)
+	}
+	if service == "" {
+		return entry, "", nil
+	}
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(kept); err != nil {
+		return "", "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), service, nil
+}
+
 func flatten(in types.MappingWithEquals) types.Mapping {
 	out := types.Mapping{}
 	if len(in) == 0 {
@@ -495,6 +796,24 @@ func sshAgentProvider(sshKeys types.SSHConfig) (session.Attachable, error) {
 }
 
 func addSecretsConfig(project *types.Project, service types.ServiceConfig) (session.Attachable, error) {
+	sources, err := buildSecretSources(project, service)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range service.Build.Secrets {
+		if secret.UID != "" || secret.GID != "" || secret.Mode != nil {
+			logrus.Warn("secrets `uid`, `gid` and `mode` are not supported by BuildKit, they will be ignored")
+			break
+		}
+	}
+	store, err := secretsprovider.NewStore(sources)
+	if err != nil {
+		return nil, err
+	}
+	return secretsprovider.NewSecretProvider(store), nil
+}
+
+func buildSecretSources(project *types.Project, service types.ServiceConfig) ([]secretsprovider.Source, error) {
 	var sources []secretsprovider.Source
 	for _, secret := range service.Build.Secrets {
 		config := project.Secrets[secret.Source]
@@ -516,15 +835,8 @@ func addSecretsConfig(project *types.Project, service types.ServiceConfig) (sess
 		default:
 			return nil, fmt.Errorf("build.secrets only supports environment or file-based secrets: %q", secret.Source)
 		}
-		if secret.UID != "" || secret.GID != "" || secret.Mode != nil {
-			logrus.Warn("secrets `uid`, `gid` and `mode` are not supported by BuildKit, they will be ignored")
-		}
 	}
-	store, err := secretsprovider.NewStore(sources)
-	if err != nil {
-		return nil, err
-	}
-	return secretsprovider.NewSecretProvider(store), nil
+	return sources, nil
 }
 
 func getImageBuildLabels(project *types.Project, service types.ServiceConfig) types.Labels {
@@ -541,14 +853,143 @@ func getImageBuildLabels(project *types.Project, service types.ServiceConfig) ty
 	return ret
 }
 
+// serviceContextPrefix is used by additional_contexts to reference another service image
+const serviceContextPrefix = "service:"
+
 func toBuildContexts(additionalContexts types.Mapping) map[string]build.NamedContext {
 	namedContexts := map[string]build.NamedContext{}
 	for name, context := range additionalContexts {
+		if service, ok := strings.CutPrefix(context, serviceContextPrefix); ok {
+			// target is resolved by buildx within the same build session
+			context = "target:" + service
+		}
 		namedContexts[name] = build.NamedContext{Path: context}
 	}
 	return namedContexts
 }
 
+// addDependencyContexts declares images built for dependencies as named contexts targeting the matching
+// build, so that `FROM <dependency image>` waits for dependency to be built within the shared build session.
+// Only dependencies the Dockerfile refers to by image are declared, as additional_contexts already wire
+// the ones referenced as `service:`
+func addDependencyContexts(project *types.Project, buildOptions map[string]build.Options) {
+	for name, opts := range buildOptions {
+		var references map[string]bool
+		for dependency := range project.Services[name].DependsOn {
+			if _, ok := buildOptions[dependency]; !ok {
+				continue
+			}
+			image := api.GetImageNameOrDefault(project.Services[dependency], project.Name)
+			if _, ok := opts.Inputs.NamedContexts[image]; ok {
+				continue
+			}
+			if references == nil {
+				references = dockerfileImageReferences(project.Services[name])
+			}
+			if !references[normalizeImageReference(image)] {
+				continue
+			}
+			if opts.Inputs.NamedContexts == nil {
+				opts.Inputs.NamedContexts = map[string]build.NamedContext{}
+			}
+			opts.Inputs.NamedContexts[image] = build.NamedContext{Path: "target:" + dependency}
+			buildOptions[name] = opts
+		}
+	}
+}
+
+// dockerfileImageReferences returns the images service Dockerfile uses as base image, COPY/ADD --from
+// or RUN --mount from source
+func dockerfileImageReferences(service types.ServiceConfig) map[string]bool {
+	references := map[string]bool{}
+	content, err := readDockerfile(service.Build)
+	if err != nil {
+		logrus.Debugf("failed to read Dockerfile for service %s: %v", service.Name, err)
+		return references
+	}
+	if content == nil {
+		return references
+	}
+	result, err := parser.Parse(bytes.NewReader(content))
+	if err != nil {
+		logrus.Debugf("failed to parse Dockerfile for service %s: %v", service.Name, err)
+		return references
+	}
+	for _, node := range result.AST.Children {
+		switch strings.ToLower(node.Value) {
+		case "from":
+			if node.Next != nil {
+				references[normalizeImageReference(node.Next.Value)] = true
+			}
+		case "copy", "add":
+			for _, flag := range node.Flags {
+				if from, ok := strings.CutPrefix(flag, "--from="); ok {
+					references[normalizeImageReference(from)] = true
+				}
+			}
+		case "run":
+			for _, flag := range node.Flags {
+				mount, ok := strings.CutPrefix(flag, "--mount=")
+				if !ok {
+					continue
+				}
+				for _, field := range strings.Split(mount, ",") {
+					if from, ok := strings.CutPrefix(field, "from="); ok {
+						references[normalizeImageReference(from)] = true
+					}
+				}
+			}
+		}
+	}
+	return references
+}
+
+// readDockerfile returns the content of the Dockerfile used to build service, or nil
+// if this one isn't available locally
+func readDockerfile(config *types.BuildConfig) ([]byte, error) {
+	if config.DockerfileInline != "" {
+		return []byte(config.DockerfileInline), nil
+	}
+	dockerfile := dockerFilePath(config.Context, config.Dockerfile)
+	if dockerfile == "" {
+		dockerfile = filepath.Join(config.Context, "Dockerfile")
+	}
+	content, err := os.ReadFile(dockerfile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return content, nil
+}
+
+// normalizeImageReference returns image reference with implicit tag, so that `base` and `base:latest` match
+func normalizeImageReference(image string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	return reference.TagNameOnly(named).String()
+}
+
+// resolveServiceContexts replaces references to services which are not part of the build session
+// by the service image, as this one has already been built or pulled
+func resolveServiceContexts(project *types.Project, buildOptions map[string]build.Options) {
+	for _, opts := range buildOptions {
+		for name, namedContext := range opts.Inputs.NamedContexts {
+			service, ok := strings.CutPrefix(namedContext.Path, "target:")
+			if !ok {
+				continue
+			}
+			if _, ok := buildOptions[service]; ok {
+				continue
+			}
+			if s, ok := project.Services[service]; ok {
+				namedContext.Path = "docker-image://" + api.GetImageNameOrDefault(s, project.Name)
+				opts.Inputs.NamedContexts[name] = namedContext
+			}
+		}
+	}
+}
+
 func parsePlatforms(service types.ServiceConfig) ([]specs.Platform, error) {
 	if service.Build == nil || len(service.Build.Platforms) == 0 {
 		return nil, nil
@@ -571,3 +1012,25 @@ func parsePlatforms(service types.ServiceConfig) ([]specs.Platform, error) {
 
 	return ret, nil
 }
+
+// writerFile exposes an io.Writer as the console.File buildx progress printer expects. Only used for
+// raw json display, which never reads from nor inspects the underlying file
+type writerFile struct {
+	io.Writer
+}
+
+func (w writerFile) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (w writerFile) Close() error {
+	return nil
+}
+
+func (w writerFile) Fd() uintptr {
+	return ^uintptr(0)
+}
+
+func (w writerFile) Name() string {
+	return "progress"
+}
@@ -34,36 +34,43 @@ import (
 	"github.com/moby/buildkit/client"
 )
 
-func (s *composeService) doBuildBuildkit(ctx context.Context, service string, opts build.Options, p *buildx.Printer, nodes []builder.Node) (string, error) {
+// doBuildBuildkit builds all services within a single build session, and returns image digest per service
+func (s *composeService) doBuildBuildkit(ctx context.Context, opts map[string]build.Options, p *buildx.Printer, nodes []builder.Node) (map[string]string, error) {
 	var (
 		response map[string]*client.SolveResponse
 		err      error
 	)
 	if s.dryRun {
-		response = s.dryRunBuildResponse(ctx, service, opts)
+		response = map[string]*client.SolveResponse{}
+		for service, o := range opts {
+			for k, v := range s.dryRunBuildResponse(ctx, service, o) {
+				response[k] = v
+			}
+		}
 	} else {
 		response, err = build.Build(ctx, nodes,
-			map[string]build.Options{service: opts},
+			opts,
 			dockerutil.NewClient(s.dockerCli),
 			confutil.ConfigDir(s.dockerCli),
-			buildx.WithPrefix(p, service, true))
+			p)
 		if err != nil {
-			return "", WrapCategorisedComposeError(err, BuildFailure)
+			return nil, WrapCategorisedComposeError(err, BuildFailure)
 		}
 	}
 
-	for _, img := range response {
+	digests := map[string]string{}
+	for service := range opts {
+		img := response[service]
 		if img == nil || len(img.ExporterResponse) == 0 {
-			continue
+			return nil, fmt.Errorf("buildkit response is missing expected result for %s", service)
 		}
 		digest, ok := img.ExporterResponse["containerimage.digest"]
 		if !ok {
-			continue
+			return nil, fmt.Errorf("buildkit response is missing expected result for %s", service)
 		}
-		return digest, nil
+		digests[service] = digest
 	}
-
-	return "", fmt.Errorf("buildkit response is missing expected result for %s", service)
+	return digests, nil
 }
 
 func (s composeService) dryRunBuildResponse(ctx context.Context, name string, options build.Options) map[string]*client.SolveResponse {
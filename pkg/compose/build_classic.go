@@ -78,6 +78,13 @@ func (s *composeService) doBuildClassic(ctx context.Context, project *types.Proj
 	if len(service.Build.Secrets) > 0 {
 		return "", fmt.Errorf("the classic builder doesn't support secrets, set DOCKER_BUILDKIT=1 to use BuildKit")
 	}
+	attests, err := serviceAttestations(service, options.Attestations)
+	if err != nil {
+		return "", err
+	}
+	if hasAttestations(attests) {
+		return "", fmt.Errorf("the classic builder doesn't support attestations, set DOCKER_BUILDKIT=1 to use BuildKit")
+	}
 
 	if service.Build.Labels == nil {
 		service.Build.Labels = make(map[string]string)
@@ -0,0 +1,222 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/watch"
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+)
+
+// commonIgnorePatterns are paths which are rarely needed by a build, and are worth adding to `.dockerignore`
+var commonIgnorePatterns = []string{
+	".git",
+	"node_modules",
+	".venv",
+	"venv",
+	"**/__pycache__",
+	".idea",
+	".vscode",
+	"coverage",
+	"**/*.log",
+	"**/.DS_Store",
+}
+
+// walkBuildContext calls fn for each file in a build context which is not excluded by `.dockerignore`
+func walkBuildContext(dir string, fn func(path string, rel string, info fs.FileInfo) error) error {
+	ignore, err := watch.LoadDockerIgnore(dir)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if entry.IsDir() {
+			skip, err := ignore.MatchesEntireDir(path)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ignored, err := ignore.Matches(path)
+		if err != nil || ignored {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(path, filepath.ToSlash(rel), info)
+	})
+}
+
+// AnalyzeBuildContext summarizes the content of service build context sent to the builder, reporting
+// the top largest files and directories
+func AnalyzeBuildContext(service types.ServiceConfig, top int) (api.BuildContextSummary, error) {
+	if service.Build == nil || isRemoteContext(service.Build.Context) {
+		return api.BuildContextSummary{Service: service.Name}, nil
+	}
+	analyzer, err := newContextAnalyzer(service)
+	if err != nil {
+		return api.BuildContextSummary{Service: service.Name}, err
+	}
+	if err := walkBuildContext(service.Build.Context, analyzer.visit); err != nil {
+		return analyzer.summary, err
+	}
+	return analyzer.result(top), nil
+}
+
+// contextAnalyzer collects a build context summary from files visited by walkBuildContext
+type contextAnalyzer struct {
+	dir        string
+	common     watch.PathMatcher
+	summary    api.BuildContextSummary
+	files      []api.BuildContextEntry
+	dirs       map[string]*api.BuildContextEntry
+	excludable map[string]*api.BuildContextEntry
+}
+
+func newContextAnalyzer(service types.ServiceConfig) (*contextAnalyzer, error) {
+	dir := service.Build.Context
+	common, err := watch.NewDockerPatternMatcher(dir, commonIgnorePatterns)
+	if err != nil {
+		return nil, err
+	}
+	return &contextAnalyzer{
+		dir:        dir,
+		common:     common,
+		summary:    api.BuildContextSummary{Service: service.Name, Context: dir},
+		dirs:       map[string]*api.BuildContextEntry{},
+		excludable: map[string]*api.BuildContextEntry{},
+	}, nil
+}
+
+func (a *contextAnalyzer) visit(path string, rel string, info fs.FileInfo) error {
+	size := info.Size()
+	a.summary.Size += size
+	a.summary.Files++
+	a.files = append(a.files, api.BuildContextEntry{Path: rel, Size: size, Files: 1})
+
+	for parent := filepath.ToSlash(filepath.Dir(rel)); parent != "."; parent = filepath.ToSlash(filepath.Dir(parent)) {
+		addToEntry(a.dirs, parent, size)
+	}
+
+	matched, err := a.common.Matches(path)
+	if err != nil || !matched {
+		return err
+	}
+	// report the top-most path matching a common pattern
+	excluded := rel
+	for parent := filepath.Dir(rel); parent != "."; parent = filepath.Dir(parent) {
+		if m, err := a.common.MatchesEntireDir(filepath.Join(a.dir, parent)); err == nil && m {
+			excluded = filepath.ToSlash(parent)
+		}
+	}
+	addToEntry(a.excludable, excluded, size)
+	return nil
+}
+
+// result returns the summary of visited files, with the top largest files and directories
+func (a *contextAnalyzer) result(top int) api.BuildContextSummary {
+	summary := a.summary
+	summary.LargestFiles = largestEntries(a.files, top)
+	summary.LargestDirs = largestEntries(entryValues(a.dirs), top)
+	summary.Excludable = largestEntries(entryValues(a.excludable), -1)
+	return summary
+}
+
+func addToEntry(entries map[string]*api.BuildContextEntry, path string, size int64) {
+	e, ok := entries[path]
+	if !ok {
+		e = &api.BuildContextEntry{Path: path}
+		entries[path] = e
+	}
+	e.Size += size
+	e.Files++
+}
+
+func entryValues(entries map[string]*api.BuildContextEntry) []api.BuildContextEntry {
+	values := make([]api.BuildContextEntry, 0, len(entries))
+	for _, e := range entries {
+		values = append(values, *e)
+	}
+	return values
+}
+
+// largestEntries sorts entries by decreasing size and keeps the top ones, all of them if top is negative
+func largestEntries(entries []api.BuildContextEntry, top int) []api.BuildContextEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Size != entries[j].Size {
+			return entries[i].Size > entries[j].Size
+		}
+		return entries[i].Path < entries[j].Path
+	})
+	if top >= 0 && len(entries) > top {
+		entries = entries[:top]
+	}
+	return entries
+}
+
+// warnLargeBuildContexts warns about services with a build context larger than the configured threshold.
+// Build context is analyzed unless this was already done while fingerprinting build inputs
+func warnLargeBuildContexts(services map[string]serviceToBuild, threshold int64) {
+	if threshold <= 0 {
+		return
+	}
+	for name, toBuild := range services {
+		summary := toBuild.context
+		if summary == nil {
+			s, err := AnalyzeBuildContext(toBuild.service, 0)
+			if err != nil {
+				logrus.Debugf("failed to analyze build context for service %q: %v", name, err)
+				continue
+			}
+			summary = &s
+		}
+		if summary.Size <= threshold {
+			continue
+		}
+		var hint []string
+		for _, e := range summary.Excludable {
+			hint = append(hint, e.Path)
+		}
+		if len(hint) > 0 {
+			logrus.Warnf("service %q build context is %s (%d files), consider adding %s to .dockerignore",
+				name, units.HumanSize(float64(summary.Size)), summary.Files, strings.Join(hint, ", "))
+			continue
+		}
+		logrus.Warnf("service %q build context is %s (%d files)", name, units.HumanSize(float64(summary.Size)), summary.Files)
+	}
+}
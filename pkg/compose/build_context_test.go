@@ -0,0 +1,90 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/compose/v2/pkg/api"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestAnalyzeBuildContext(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) {
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NilError(t, os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644))
+	}
+	write("Dockerfile", 10)
+	write(".dockerignore", 6)
+	write("src/main.go", 100)
+	write("node_modules/lib/index.js", 1000)
+	write("node_modules/lib/package.json", 50)
+	write("app.log", 20)
+	write("data/dump.sql", 5000)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("data\n"), 0o644))
+
+	summary, err := AnalyzeBuildContext(types.ServiceConfig{
+		Name:  "app",
+		Build: &types.BuildConfig{Context: dir},
+	}, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, summary.Files, 6)
+	assert.Equal(t, summary.Size, int64(10+5+100+1000+50+20))
+	assert.DeepEqual(t, summary.LargestFiles, []api.BuildContextEntry{
+		{Path: "node_modules/lib/index.js", Size: 1000, Files: 1},
+		{Path: "src/main.go", Size: 100, Files: 1},
+	})
+	assert.DeepEqual(t, summary.LargestDirs, []api.BuildContextEntry{
+		{Path: "node_modules", Size: 1050, Files: 2},
+		{Path: "node_modules/lib", Size: 1050, Files: 2},
+	})
+	assert.DeepEqual(t, summary.Excludable, []api.BuildContextEntry{
+		{Path: "node_modules", Size: 1050, Files: 2},
+		{Path: "app.log", Size: 20, Files: 1},
+	})
+}
+
+func TestLabelBuildHashesAnalyzesContext(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch"), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "100%.log"), []byte(strings.Repeat("x", 100)), 0o644))
+
+	service := types.ServiceConfig{Name: "app", Build: &types.BuildConfig{Context: dir}}
+	project := &types.Project{Name: "test", Services: types.Services{"app": service}}
+	services := map[string]serviceToBuild{"app": {name: "app", service: service}}
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(configfile.New("test")).AnyTimes()
+	apiClient.EXPECT().DaemonHost().Return("unix:///var/run/docker.sock").AnyTimes()
+	tested := composeService{dockerCli: cli}
+	_, err := tested.labelBuildHashes(project, services, api.BuildOptions{ContextSizeWarning: 1})
+	assert.NilError(t, err)
+
+	expected, err := AnalyzeBuildContext(service, 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *services["app"].context, expected)
+	assert.DeepEqual(t, expected.Excludable, []api.BuildContextEntry{{Path: "100%.log", Size: 100, Files: 1}})
+}
@@ -0,0 +1,268 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/build"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/compose/v2/pkg/api"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestServiceContexts(t *testing.T) {
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"base": {Name: "base", Build: &types.BuildConfig{Context: "."}},
+			"lib":  {Name: "lib", Image: "lib:latest", Build: &types.BuildConfig{Context: "."}},
+			"app": {Name: "app", Build: &types.BuildConfig{
+				Context: ".",
+				AdditionalContexts: types.Mapping{
+					"base": "service:base",
+					"lib":  "service:lib",
+					"src":  "./src",
+				},
+			}},
+		},
+	}
+	contexts := toBuildContexts(project.Services["app"].Build.AdditionalContexts)
+	assert.DeepEqual(t, contexts, map[string]build.NamedContext{
+		"base": {Path: "target:base"},
+		"lib":  {Path: "target:lib"},
+		"src":  {Path: "./src"},
+	})
+
+	// lib is not part of the build session, so its image is used instead
+	buildOptions := map[string]build.Options{
+		"base": {},
+		"app":  {Inputs: build.Inputs{NamedContexts: contexts}},
+	}
+	resolveServiceContexts(project, buildOptions)
+	assert.DeepEqual(t, buildOptions["app"].Inputs.NamedContexts, map[string]build.NamedContext{
+		"base": {Path: "target:base"},
+		"lib":  {Path: "docker-image://lib:latest"},
+		"src":  {Path: "./src"},
+	})
+}
+
+func TestDependencyContexts(t *testing.T) {
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"base":  {Name: "base", Image: "base", Build: &types.BuildConfig{Context: "."}},
+			"tools": {Name: "tools", Image: "tools", Build: &types.BuildConfig{Context: "."}},
+			"other": {Name: "other", Build: &types.BuildConfig{Context: "."}},
+			"db":    {Name: "db", Image: "postgres"},
+			"service": {
+				Name: "service",
+				Build: &types.BuildConfig{
+					Context:          ".",
+					DockerfileInline: "FROM base:latest\nCOPY --from=tools /bin/tool /bin/tool\n",
+				},
+				DependsOn: types.DependsOnConfig{
+					"base":  {Condition: types.ServiceConditionStarted},
+					"tools": {Condition: types.ServiceConditionStarted},
+					"other": {Condition: types.ServiceConditionStarted},
+					"db":    {Condition: types.ServiceConditionStarted},
+				},
+			},
+		},
+	}
+	buildOptions := map[string]build.Options{
+		"base":    {},
+		"tools":   {},
+		"other":   {},
+		"service": {},
+	}
+	addDependencyContexts(project, buildOptions)
+	assert.DeepEqual(t, buildOptions["service"].Inputs.NamedContexts, map[string]build.NamedContext{
+		"base":  {Path: "target:base"},
+		"tools": {Path: "target:tools"},
+	})
+	assert.Equal(t, len(buildOptions["base"].Inputs.NamedContexts), 0)
+}
+
+func TestDockerfileImageReferences(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(`FROM golang AS builder
+RUN --mount=type=cache,target=/cache,from=cache/image:1.0 go build
+FROM scratch
+COPY --from=builder /app /app
+`), 0o600))
+
+	references := dockerfileImageReferences(types.ServiceConfig{Build: &types.BuildConfig{Context: dir}})
+	assert.DeepEqual(t, references, map[string]bool{
+		"docker.io/library/golang:latest":  true,
+		"docker.io/cache/image:1.0":        true,
+		"docker.io/library/scratch:latest": true,
+		"docker.io/library/builder:latest": true,
+	})
+
+	references = dockerfileImageReferences(types.ServiceConfig{Build: &types.BuildConfig{Context: filepath.Join(dir, "missing")}})
+	assert.Equal(t, len(references), 0)
+}
+
+func TestCheckBuildHashes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(configfile.New("test")).AnyTimes()
+	apiClient.EXPECT().DaemonHost().Return("unix:///var/run/docker.sock").AnyTimes()
+	tested := composeService{dockerCli: cli}
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{Context: t.TempDir()}},
+		},
+	}
+	toBuild := func() map[string]serviceToBuild {
+		return map[string]serviceToBuild{"app": {name: "app", service: project.Services["app"]}}
+	}
+
+	services := toBuild()
+	hash, err := BuildHash(project.Services["app"], resolveAndMergeBuildArgs(cli, project, project.Services["app"], api.BuildOptions{}))
+	assert.NilError(t, err)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "test-app").Return(moby.ImageInspect{
+		ID:     "sha256:built",
+		Config: &container.Config{Labels: map[string]string{api.ImageBuildHashLabel: hash}},
+	}, nil, nil)
+	upToDate, err := tested.checkBuildHashes(context.Background(), project, services, api.BuildOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, upToDate, map[string]string{"app": "sha256:built"})
+
+	// --pull must check for a newer base image, so build is not skipped
+	upToDate, err = tested.checkBuildHashes(context.Background(), project, toBuild(), api.BuildOptions{Pull: true})
+	assert.NilError(t, err)
+	assert.Equal(t, len(upToDate), 0)
+}
+
+func TestBuildCanBeSkipped(t *testing.T) {
+	service := types.ServiceConfig{Name: "app", Build: &types.BuildConfig{Context: "."}}
+	tests := []struct {
+		name     string
+		options  api.BuildOptions
+		expected bool
+	}{
+		{name: "default", expected: true},
+		{name: "force", options: api.BuildOptions{Force: true}},
+		{name: "push", options: api.BuildOptions{Push: true}},
+		{name: "cache-from", options: api.BuildOptions{CacheFrom: []string{"user/app:cache"}}},
+		{name: "cache-to", options: api.BuildOptions{CacheTo: []string{"type=inline"}}},
+		{name: "attest", options: api.BuildOptions{Attestations: []string{"type=sbom"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, buildCanBeSkipped(service, tt.options), tt.expected)
+		})
+	}
+}
+
+func TestLabelBuildHashesForced(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	_, cli := prepareMocks(mockCtrl)
+	tested := composeService{dockerCli: cli}
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			// context doesn't exist, so it would fail to be fingerprinted
+			"app": {Name: "app", Build: &types.BuildConfig{Context: filepath.Join(t.TempDir(), "missing")}},
+		},
+	}
+	services := map[string]serviceToBuild{"app": {name: "app", service: project.Services["app"]}}
+	hashes, err := tested.labelBuildHashes(project, services, api.BuildOptions{Force: true})
+	assert.NilError(t, err)
+	assert.Equal(t, len(hashes), 0)
+	_, labeled := services["app"].service.Build.Labels[api.ImageBuildHashLabel]
+	assert.Assert(t, !labeled)
+}
+
+func TestServiceCacheEntries(t *testing.T) {
+	web := types.ServiceConfig{Name: "web"}
+	db := types.ServiceConfig{Name: "db"}
+	overrides := []string{
+		"type=registry,ref=user/web:cache,service=web",
+		"user/all:cache",
+	}
+
+	entries, err := serviceCacheEntries(web, []string{"type=local,src=/tmp/cache"}, overrides, false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 3)
+	assert.Equal(t, entries[0].Type, "local")
+	assert.Equal(t, entries[1].Attrs["ref"], "user/web:cache")
+	assert.Equal(t, entries[2].Attrs["ref"], "user/all:cache")
+
+	entries, err = serviceCacheEntries(db, []string{"type=local,src=/tmp/cache"}, overrides, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Attrs["ref"], "user/all:cache")
+
+	// no override targets db, so its own cache configuration is kept
+	entries, err = serviceCacheEntries(db, []string{"type=local,dest=/tmp/cache"}, nil, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Type, "local")
+
+	perService := []string{"type=registry,ref=user/web:cache,service=web"}
+	entries, err = serviceCacheEntries(web, []string{"type=local,src=/tmp/cache"}, perService, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Attrs["ref"], "user/web:cache")
+
+	entries, err = serviceCacheEntries(db, []string{"type=local,src=/tmp/cache"}, perService, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Type, "local")
+}
+
+func TestServiceAttestations(t *testing.T) {
+	service := types.ServiceConfig{
+		Name: "web",
+		Build: &types.BuildConfig{
+			Context: ".",
+			Extensions: types.Extensions{
+				"x-attestations": map[string]any{
+					"provenance": "mode=max",
+					"sbom":       false,
+				},
+			},
+		},
+	}
+	attests, err := serviceAttestations(service, []string{"type=sbom", "type=provenance,mode=min"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(attests), 2)
+	assert.Equal(t, *attests["provenance"], "type=provenance,mode=max")
+	assert.Assert(t, attests["sbom"] == nil, "service extension disables sbom")
+	assert.Assert(t, hasAttestations(attests))
+	assert.Equal(t, attestationsSummary(attests), "provenance")
+
+	service.Build.Extensions = nil
+	attests, err = serviceAttestations(service, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !hasAttestations(attests))
+}
@@ -0,0 +1,406 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"golang.org/x/exp/maps"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	"github.com/docker/compose/v2/pkg/utils"
+)
+
+const (
+	// bundleVersion is the version of the bundle archive format
+	bundleVersion = 1
+	// bundleManifestEntry describes bundle content
+	bundleManifestEntry = "bundle.json"
+	// bundleProjectDir holds compose and env files, relative to project directory
+	bundleProjectDir = "project"
+	// bundleImagesEntry is the images archive, as produced by the engine image export API
+	bundleImagesEntry = "images.tar"
+	// bundleImageDigestsFile is the compose override pinning service images to their digest, as published
+	// by `compose publish --resolve-image-digests`, relative to project directory
+	bundleImageDigestsFile = "image-digests.yaml"
+)
+
+func (s *composeService) Bundle(ctx context.Context, project *types.Project, options api.BundleOptions) error {
+	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		return s.bundle(ctx, project, options)
+	}, s.stdinfo(), "Bundling")
+}
+
+func (s *composeService) bundle(ctx context.Context, project *types.Project, options api.BundleOptions) error {
+	if options.Platform != "" {
+		for name, service := range project.Services {
+			if service.Platform == "" {
+				service.Platform = options.Platform
+				project.Services[name] = service
+			}
+		}
+	}
+	err := s.ensureImagesExists(ctx, project, &api.BuildOptions{Progress: progress.Mode}, false)
+	if err != nil {
+		return err
+	}
+
+	manifest := api.BundleManifest{
+		Version:  bundleVersion,
+		Name:     project.Name,
+		Profiles: project.Profiles,
+	}
+	for _, file := range project.ComposeFiles {
+		rel, err := projectRelativePath(project.WorkingDir, file)
+		if err != nil {
+			return err
+		}
+		manifest.ComposeFiles = append(manifest.ComposeFiles, rel)
+	}
+	for _, file := range options.EnvFiles {
+		rel, err := projectRelativePath(project.WorkingDir, file)
+		if err != nil {
+			return err
+		}
+		manifest.EnvFiles = append(manifest.EnvFiles, rel)
+	}
+	files, err := bundleFiles(project, options)
+	if err != nil {
+		return err
+	}
+	if utils.StringContains(files, bundleImageDigestsFile) {
+		return fmt.Errorf("project file %s conflicts with bundled image digests", bundleImageDigestsFile)
+	}
+	digests, err := s.generateImageDigestsOverride(ctx, project)
+	if err != nil {
+		return err
+	}
+	manifest.ImageDigests = bundleImageDigestsFile
+
+	var images []string
+	for _, name := range project.ServiceNames() {
+		service := project.Services[name]
+		imageName := api.GetImageNameOrDefault(service, project.Name)
+		inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, imageName)
+		if err != nil {
+			return err
+		}
+		image := api.BundleImage{
+			Service:  name,
+			Image:    imageName,
+			ID:       inspect.ID,
+			Platform: inspect.Os + "/" + inspect.Architecture,
+		}
+		if inspect.Variant != "" {
+			image.Platform += "/" + inspect.Variant
+		}
+		manifest.Images = append(manifest.Images, image)
+		if !utils.StringContains(images, imageName) {
+			images = append(images, imageName)
+		}
+	}
+
+	if s.dryRun {
+		return nil
+	}
+	out, err := os.Create(options.Output)
+	if err != nil {
+		return err
+	}
+	defer out.Close() //nolint:errcheck
+	tw := tar.NewWriter(out)
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeBundleEntry(tw, bundleManifestEntry, int64(len(content)), strings.NewReader(string(content))); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := addBundleFile(tw, filepath.Join(project.WorkingDir, file), path.Join(bundleProjectDir, filepath.ToSlash(file))); err != nil {
+			return err
+		}
+	}
+	if err := writeBundleEntry(tw, path.Join(bundleProjectDir, bundleImageDigestsFile), int64(len(digests)), bytes.NewReader(digests)); err != nil {
+		return err
+	}
+
+	w := progress.ContextWriter(ctx)
+	eventName := "Saving images"
+	w.Event(progress.NewEvent(eventName, progress.Working, ""))
+	if err := s.addBundleImages(ctx, tw, images); err != nil {
+		w.Event(progress.ErrorEvent(eventName))
+		return err
+	}
+	w.Event(progress.NewEvent(eventName, progress.Done, "Saved"))
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// bundleFiles lists local files the project depends on, relative to project directory: compose and env files,
+// included and extended compose files, and file-based configs and secrets
+func bundleFiles(project *types.Project, options api.BundleOptions) ([]string, error) {
+	var files []string
+	add := func(file string) error {
+		rel, err := projectRelativePath(project.WorkingDir, file)
+		if err != nil {
+			return err
+		}
+		if !utils.StringContains(files, rel) {
+			files = append(files, rel)
+		}
+		return nil
+	}
+
+	for _, file := range project.ComposeFiles {
+		if err := add(file); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range options.EnvFiles {
+		if err := add(file); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range options.ModelFiles {
+		if err := add(file); err != nil {
+			return nil, err
+		}
+	}
+	// disabled services are included, as loader still requires their env files
+	services := project.AllServices()
+	names := maps.Keys(services)
+	sort.Strings(names)
+	for _, name := range names {
+		for _, envFile := range services[name].EnvFiles {
+			if _, err := os.Stat(envFile.Path); err != nil && !envFile.Required {
+				continue
+			}
+			if err := add(envFile.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	names = maps.Keys(project.Configs)
+	sort.Strings(names)
+	for _, name := range names {
+		if file := project.Configs[name].File; file != "" {
+			if err := add(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	names = maps.Keys(project.Secrets)
+	sort.Strings(names)
+	for _, name := range names {
+		if file := project.Secrets[name].File; file != "" {
+			if err := add(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// addBundleImages exports images through the engine API. Tar entries require a size, so images archive
+// is first written to a temporary file
+func (s *composeService) addBundleImages(ctx context.Context, tw *tar.Writer, images []string) error {
+	stream, err := s.apiClient().ImageSave(ctx, images)
+	if err != nil {
+		return err
+	}
+	defer stream.Close() //nolint:errcheck
+
+	tmp, err := os.CreateTemp("", "compose-bundle-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := io.Copy(tmp, stream); err != nil {
+		return err
+	}
+	return addBundleFile(tw, tmp.Name(), bundleImagesEntry)
+}
+
+// projectRelativePath returns file path relative to project directory. Files outside project directory can't be
+// bundled nor published, as they could not be resolved once extracted
+func projectRelativePath(workingDir string, file string) (string, error) {
+	rel, err := filepath.Rel(workingDir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside project directory %s", file, workingDir)
+	}
+	return rel, nil
+}
+
+func addBundleFile(tw *tar.Writer, file string, name string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return writeBundleEntry(tw, name, info.Size(), f)
+}
+
+func writeBundleEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, r)
+	return err
+}
+
+func (s *composeService) Unbundle(ctx context.Context, options api.UnbundleOptions) (api.BundleManifest, error) {
+	var manifest api.BundleManifest
+	err := progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		var err error
+		manifest, err = s.unbundle(ctx, options)
+		return err
+	}, s.stdinfo(), "Loading bundle")
+	return manifest, err
+}
+
+func (s *composeService) unbundle(ctx context.Context, options api.UnbundleOptions) (api.BundleManifest, error) {
+	var manifest api.BundleManifest
+	in, err := os.Open(options.Input)
+	if err != nil {
+		return manifest, err
+	}
+	defer in.Close() //nolint:errcheck
+
+	w := progress.ContextWriter(ctx)
+	eventName := "Loading images"
+	tr := tar.NewReader(in)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return manifest, err
+		}
+		switch {
+		case header.Name == bundleManifestEntry:
+			if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+				return manifest, fmt.Errorf("invalid bundle manifest: %w", err)
+			}
+			if manifest.Version != bundleVersion {
+				return manifest, fmt.Errorf("unsupported bundle version %d", manifest.Version)
+			}
+		case header.Name == bundleImagesEntry:
+			w.Event(progress.NewEvent(eventName, progress.Working, ""))
+			if err := s.loadBundleImages(ctx, tr); err != nil {
+				w.Event(progress.ErrorEvent(eventName))
+				return manifest, err
+			}
+			w.Event(progress.NewEvent(eventName, progress.Done, "Loaded"))
+		case strings.HasPrefix(header.Name, bundleProjectDir+"/"):
+			if err := extractBundleFile(options.Directory, strings.TrimPrefix(header.Name, bundleProjectDir+"/"), tr); err != nil {
+				return manifest, err
+			}
+		}
+	}
+	if manifest.Version == 0 {
+		return manifest, fmt.Errorf("%s is not a compose bundle", options.Input)
+	}
+
+	// check loaded images are the ones bundle was created with
+	sort.Slice(manifest.Images, func(i, j int) bool {
+		return manifest.Images[i].Service < manifest.Images[j].Service
+	})
+	for _, image := range manifest.Images {
+		inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, image.Image)
+		if err != nil {
+			return manifest, fmt.Errorf("service %q: image %s not loaded from bundle: %w", image.Service, image.Image, err)
+		}
+		if inspect.ID != image.ID {
+			return manifest, fmt.Errorf("service %q: image %s has ID %s, expected %s", image.Service, image.Image, inspect.ID, image.ID)
+		}
+	}
+	return manifest, nil
+}
+
+func (s *composeService) loadBundleImages(ctx context.Context, r io.Reader) error {
+	resp, err := s.apiClient().ImageLoad(ctx, r, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var jm jsonmessage.JSONMessage
+		if err := dec.Decode(&jm); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if jm.Error != nil {
+			return errors.New(jm.Error.Message)
+		}
+	}
+}
+
+// extractBundleFile writes a project file from bundle into directory, refusing paths escaping it
+func extractBundleFile(dir string, name string, r io.Reader) error {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if _, err := projectRelativePath(dir, target); err != nil {
+		return fmt.Errorf("invalid bundle entry %q", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,146 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	moby "github.com/docker/docker/api/types"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestProjectRelativePath(t *testing.T) {
+	rel, err := projectRelativePath("/project", "/project/sub/compose.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, rel, filepath.Join("sub", "compose.yaml"))
+
+	_, err = projectRelativePath("/project", "/other/compose.yaml")
+	assert.ErrorContains(t, err, "is outside project directory")
+
+	_, err = projectRelativePath("/project", "/project/../etc/passwd")
+	assert.ErrorContains(t, err, "is outside project directory")
+}
+
+func TestBundleFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"compose.yaml", ".env", "base.yaml", "web.env", "nginx.conf", "db_password.txt"} {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, file), nil, 0o600))
+	}
+	project := &types.Project{
+		WorkingDir:   dir,
+		ComposeFiles: []string{filepath.Join(dir, "compose.yaml")},
+		Services: types.Services{
+			"web": {
+				Name: "web",
+				EnvFiles: []types.EnvFile{
+					{Path: filepath.Join(dir, "web.env"), Required: true},
+					{Path: filepath.Join(dir, "optional.env")},
+				},
+			},
+		},
+		Configs: types.Configs{
+			"nginx":  {File: filepath.Join(dir, "nginx.conf")},
+			"inline": {Content: "inline"},
+		},
+		Secrets: types.Secrets{
+			"db_password": {File: filepath.Join(dir, "db_password.txt")},
+			"external":    {External: true},
+		},
+	}
+
+	files, err := bundleFiles(project, api.BundleOptions{
+		EnvFiles:   []string{filepath.Join(dir, ".env")},
+		ModelFiles: []string{filepath.Join(dir, "base.yaml"), filepath.Join(dir, "compose.yaml")},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{"compose.yaml", ".env", "base.yaml", "web.env", "nginx.conf", "db_password.txt"})
+
+	project.Configs["outside"] = types.ConfigObjConfig{File: filepath.Join(filepath.Dir(dir), "outside.conf")}
+	_, err = bundleFiles(project, api.BundleOptions{})
+	assert.ErrorContains(t, err, "is outside project directory")
+}
+
+func TestUnbundle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	manifest := api.BundleManifest{
+		Version:      bundleVersion,
+		Name:         "test",
+		ComposeFiles: []string{"compose.yaml"},
+		ImageDigests: bundleImageDigestsFile,
+		Images: []api.BundleImage{
+			{Service: "web", Image: "nginx", ID: "sha256:abc"},
+		},
+	}
+	bundle := filepath.Join(t.TempDir(), "bundle.tar")
+	f, err := os.Create(bundle)
+	assert.NilError(t, err)
+	tw := tar.NewWriter(f)
+	content, err := json.Marshal(manifest)
+	assert.NilError(t, err)
+	assert.NilError(t, writeBundleEntry(tw, bundleManifestEntry, int64(len(content)), strings.NewReader(string(content))))
+	compose := "services:\n  web:\n    image: nginx\n"
+	assert.NilError(t, writeBundleEntry(tw, "project/compose.yaml", int64(len(compose)), strings.NewReader(compose)))
+	digests := "services:\n  web:\n    image: docker.io/library/nginx:latest@sha256:def\n"
+	assert.NilError(t, writeBundleEntry(tw, "project/"+bundleImageDigestsFile, int64(len(digests)), strings.NewReader(digests)))
+	assert.NilError(t, writeBundleEntry(tw, bundleImagesEntry, 6, strings.NewReader("images")))
+	assert.NilError(t, tw.Close())
+	assert.NilError(t, f.Close())
+
+	apiClient.EXPECT().ImageLoad(gomock.Any(), gomock.Any(), true).
+		DoAndReturn(func(_ context.Context, r io.Reader, _ bool) (moby.ImageLoadResponse, error) {
+			b, err := io.ReadAll(r)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), "images")
+			return moby.ImageLoadResponse{Body: io.NopCloser(strings.NewReader(`{"stream":"Loaded image: nginx:latest"}`))}, nil
+		})
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "nginx").Return(moby.ImageInspect{ID: "sha256:abc"}, nil, nil)
+
+	dir := t.TempDir()
+	loaded, err := tested.unbundle(context.Background(), api.UnbundleOptions{Input: bundle, Directory: dir})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded, manifest)
+
+	extracted, err := os.ReadFile(filepath.Join(dir, "compose.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(extracted), compose)
+	extracted, err = os.ReadFile(filepath.Join(dir, loaded.ImageDigests))
+	assert.NilError(t, err)
+	assert.Equal(t, string(extracted), digests)
+}
+
+func TestExtractBundleFileOutsideDirectory(t *testing.T) {
+	err := extractBundleFile(t.TempDir(), "../escape.yaml", strings.NewReader(""))
+	assert.Error(t, err, `invalid bundle entry "../escape.yaml"`)
+}
@@ -59,6 +59,8 @@ func NewComposeService(dockerCli command.Cli) api.Service {
 		clock:          clockwork.NewRealClock(),
 		maxConcurrency: -1,
 		dryRun:         false,
+
+		registryAttempts: defaultRegistryAttempts,
 	}
 }
 
@@ -70,6 +72,9 @@ type composeService struct {
 	clock          clockwork.Clock
 	maxConcurrency int
 	dryRun         bool
+
+	registryAttempts int
+	registryLimiter  *registryLimiter
 }
 
 // Close releases any connections/resources held by the underlying clients.
@@ -18,7 +18,6 @@ package compose
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -174,14 +173,13 @@ func (c *convergence) ensureService(ctx context.Context, project *types.Project,
 
 		// Enforce non-diverged containers are running
 		w := progress.ContextWriter(ctx)
-		name := getContainerProgressName(container)
 		switch container.State {
 		case ContainerRunning:
-			w.Event(progress.RunningEvent(name))
+			notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRunning))
 		case ContainerCreated:
 		case ContainerRestarting:
 		case ContainerExited:
-			w.Event(progress.CreatedEvent(name))
+			notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleCreated))
 		default:
 			container := container
 			eg.Go(tracing.EventWrapFuncForErrGroup(ctx, "service/start", tracing.ContainerOptions(container), func(ctx context.Context) error {
@@ -324,22 +322,6 @@ func getContainerProgressName(container moby.Container) string {
 	return "Container " + getCanonicalContainerName(container)
 }
 
-func containerEvents(containers Containers, eventFunc func(string) progress.Event) []progress.Event {
-	events := []progress.Event{}
-	for _, container := range containers {
-		events = append(events, eventFunc(getContainerProgressName(container)))
-	}
-	return events
-}
-
-func containerReasonEvents(containers Containers, eventFunc func(string, string) progress.Event, reason string) []progress.Event {
-	events := []progress.Event{}
-	for _, container := range containers {
-		events = append(events, eventFunc(getContainerProgressName(container), reason))
-	}
-	return events
-}
-
 // ServiceConditionRunningOrHealthy is a service condition on status running or healthy
 const ServiceConditionRunningOrHealthy = "running_or_healthy"
 
@@ -355,7 +337,7 @@ func (s *composeService) waitDependencies(ctx context.Context, project *types.Pr
 		}
 
 		waitingFor := containers.filter(isService(dep))
-		w.Events(containerEvents(waitingFor, progress.Waiting))
+		notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleWaiting, "", nil)
 		if len(waitingFor) == 0 {
 			if config.Required {
 				return fmt.Errorf("%s is missing dependency %s", dependant, dep)
@@ -379,29 +361,29 @@ func (s *composeService) waitDependencies(ctx context.Context, project *types.Pr
 					healthy, err := s.isServiceHealthy(ctx, waitingFor, true)
 					if err != nil {
 						if !config.Required {
-							w.Events(containerReasonEvents(waitingFor, progress.SkippedEvent, fmt.Sprintf("optional dependency %q is not running or is unhealthy", dep)))
+							notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleSkipped, fmt.Sprintf("optional dependency %q is not running or is unhealthy", dep), nil)
 							logrus.Warnf("optional dependency %q is not running or is unhealthy: %s", dep, err.Error())
 							return nil
 						}
 						return err
 					}
 					if healthy {
-						w.Events(containerEvents(waitingFor, progress.Healthy))
+						notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleHealthy, "", nil)
 						return nil
 					}
 				case types.ServiceConditionHealthy:
 					healthy, err := s.isServiceHealthy(ctx, waitingFor, false)
 					if err != nil {
 						if !config.Required {
-							w.Events(containerReasonEvents(waitingFor, progress.SkippedEvent, fmt.Sprintf("optional dependency %q failed to start", dep)))
+							notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleSkipped, fmt.Sprintf("optional dependency %q failed to start", dep), nil)
 							logrus.Warnf("optional dependency %q failed to start: %s", dep, err.Error())
 							return nil
 						}
-						w.Events(containerEvents(waitingFor, progress.ErrorEvent))
+						notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleFailed, err.Error(), err)
 						return fmt.Errorf("dependency failed to start: %w", err)
 					}
 					if healthy {
-						w.Events(containerEvents(waitingFor, progress.Healthy))
+						notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleHealthy, "", nil)
 						return nil
 					}
 				case types.ServiceConditionCompletedSuccessfully:
@@ -411,21 +393,21 @@ func (s *composeService) waitDependencies(ctx context.Context, project *types.Pr
 					}
 					if exited {
 						if code == 0 {
-							w.Events(containerEvents(waitingFor, progress.Exited))
+							notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleExited, "", nil)
 							return nil
 						}
 
 						messageSuffix := fmt.Sprintf("%q didn't complete successfully: exit %d", dep, code)
 						if !config.Required {
 							// optional -> mark as skipped & don't propagate error
-							w.Events(containerReasonEvents(waitingFor, progress.SkippedEvent, fmt.Sprintf("optional dependency %s", messageSuffix)))
+							notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleSkipped, fmt.Sprintf("optional dependency %s", messageSuffix), nil)
 							logrus.Warnf("optional dependency %s", messageSuffix)
 							return nil
 						}
 
-						msg := fmt.Sprintf("service %s", messageSuffix)
-						w.Events(containerReasonEvents(waitingFor, progress.ErrorMessageEvent, msg))
-						return errors.New(msg)
+						err := fmt.Errorf("service %s", messageSuffix)
+						notifyContainersLifecycle(ctx, w, waitingFor, api.LifecycleFailed, err.Error(), err)
+						return err
 					}
 				default:
 					logrus.Warnf("unsupported depends_on condition: %s", config.Condition)
@@ -480,13 +462,23 @@ func nextContainerNumber(containers []moby.Container) int {
 func (s *composeService) createContainer(ctx context.Context, project *types.Project, service types.ServiceConfig,
 	name string, number int, opts createOptions) (container moby.Container, err error) {
 	w := progress.ContextWriter(ctx)
-	eventName := "Container " + name
-	w.Event(progress.CreatingEvent(eventName))
+	event := api.LifecycleEvent{
+		Project:   project.Name,
+		Service:   service.Name,
+		Container: name,
+		Number:    number,
+		Action:    api.LifecycleCreating,
+	}
+	notifyLifecycle(ctx, w, event)
 	container, err = s.createMobyContainer(ctx, project, service, name, number, nil, opts, w)
 	if err != nil {
+		event.Action = api.LifecycleFailed
+		event.Reason = "Error while Creating"
+		event.Error = err
+		notifyLifecycle(ctx, w, event)
 		return
 	}
-	w.Event(progress.CreatedEvent(eventName))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleCreated))
 	return
 }
 
@@ -494,7 +486,7 @@ func (s *composeService) recreateContainer(ctx context.Context, project *types.P
 	replaced moby.Container, inherit bool, timeout *time.Duration) (moby.Container, error) {
 	var created moby.Container
 	w := progress.ContextWriter(ctx)
-	w.Event(progress.NewEvent(getContainerProgressName(replaced), progress.Working, "Recreate"))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(replaced, api.LifecycleRecreating))
 
 	number, err := strconv.Atoi(replaced.Labels[api.ContainerNumberLabel])
 	if err != nil {
@@ -534,7 +526,9 @@ func (s *composeService) recreateContainer(ctx context.Context, project *types.P
 		return created, err
 	}
 
-	w.Event(progress.NewEvent(getContainerProgressName(replaced), progress.Done, "Recreated"))
+	event := containerLifecycleEvent(replaced, api.LifecycleRecreated)
+	event.ContainerID = created.ID
+	notifyLifecycle(ctx, w, event)
 	setDependentLifecycle(project, service.Name, forceRecreate)
 	return created, err
 }
@@ -557,12 +551,12 @@ func setDependentLifecycle(project *types.Project, service string, strategy stri
 
 func (s *composeService) startContainer(ctx context.Context, container moby.Container) error {
 	w := progress.ContextWriter(ctx)
-	w.Event(progress.NewEvent(getContainerProgressName(container), progress.Working, "Restart"))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRestarting))
 	err := s.apiClient().ContainerStart(ctx, container.ID, containerType.StartOptions{})
 	if err != nil {
 		return err
 	}
-	w.Event(progress.NewEvent(getContainerProgressName(container), progress.Done, "Restarted"))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRestarted))
 	return nil
 }
 
@@ -779,13 +773,13 @@ func (s *composeService) startService(ctx context.Context, project *types.Projec
 		if container.State == ContainerRunning {
 			continue
 		}
-		eventName := getContainerProgressName(container)
-		w.Event(progress.StartingEvent(eventName))
+		notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleStarting))
 		err := s.apiClient().ContainerStart(ctx, container.ID, containerType.StartOptions{})
 		if err != nil {
+			notifyContainersLifecycle(ctx, w, Containers{container}, api.LifecycleFailed, "Error while Starting", err)
 			return err
 		}
-		w.Event(progress.StartedEvent(eventName))
+		notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleStarted))
 	}
 	return nil
 }
@@ -288,15 +288,14 @@ func (s *composeService) removeVolume(ctx context.Context, id string, w progress
 }
 
 func (s *composeService) stopContainer(ctx context.Context, w progress.Writer, container moby.Container, timeout *time.Duration) error {
-	eventName := getContainerProgressName(container)
-	w.Event(progress.StoppingEvent(eventName))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleStopping))
 	timeoutInSecond := utils.DurationSecondToInt(timeout)
 	err := s.apiClient().ContainerStop(ctx, container.ID, containerType.StopOptions{Timeout: timeoutInSecond})
 	if err != nil {
-		w.Event(progress.ErrorMessageEvent(eventName, "Error while Stopping"))
+		notifyContainersLifecycle(ctx, w, Containers{container}, api.LifecycleFailed, "Error while Stopping", err)
 		return err
 	}
-	w.Event(progress.StoppedEvent(eventName))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleStopped))
 	return nil
 }
 
@@ -324,21 +323,20 @@ func (s *composeService) removeContainers(ctx context.Context, containers []moby
 
 func (s *composeService) stopAndRemoveContainer(ctx context.Context, container moby.Container, timeout *time.Duration, volumes bool) error {
 	w := progress.ContextWriter(ctx)
-	eventName := getContainerProgressName(container)
 	err := s.stopContainer(ctx, w, container, timeout)
 	if err != nil {
 		return err
 	}
-	w.Event(progress.RemovingEvent(eventName))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRemoving))
 	err = s.apiClient().ContainerRemove(ctx, container.ID, containerType.RemoveOptions{
 		Force:         true,
 		RemoveVolumes: volumes,
 	})
 	if err != nil && !errdefs.IsNotFound(err) && !errdefs.IsConflict(err) {
-		w.Event(progress.ErrorMessageEvent(eventName, "Error while Removing"))
+		notifyContainersLifecycle(ctx, w, Containers{container}, api.LifecycleFailed, "Error while Removing", err)
 		return err
 	}
-	w.Event(progress.RemovedEvent(eventName))
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRemoved))
 	return nil
 }
 
@@ -0,0 +1,777 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+const (
+	// exportWaitImage is the image used by init containers waiting for a dependency to be reachable
+	exportWaitImage = "busybox:1.36"
+	// exportVolumeSize is the storage requested by persistent volume claims created for named volumes
+	exportVolumeSize = "1Gi"
+)
+
+// Files declaring resources shared by services. Service files are named after the service Kubernetes name, which
+// can't contain an underscore, so they never collide
+const (
+	exportVolumesFile = "_volumes.yaml"
+	exportConfigsFile = "_configs.yaml"
+	exportSecretsFile = "_secrets.yaml"
+)
+
+func (s *composeService) Export(_ context.Context, project *types.Project, options api.ExportOptions) ([]string, error) {
+	export := kubernetesExport{
+		project: project,
+		files:   map[string][]any{},
+		volumes: map[string]bool{},
+		configs: map[string]bool{},
+		secrets: map[string]bool{},
+	}
+	var services []string
+	err := project.ForEachService(options.Services, func(name string, _ *types.ServiceConfig) error {
+		services = append(services, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKubernetesNames("services", services); err != nil {
+		return nil, err
+	}
+	if err := checkKubernetesNames("volumes", sortedKeys(project.Volumes)); err != nil {
+		return nil, err
+	}
+	if err := checkKubernetesNames("configs", sortedKeys(project.Configs)); err != nil {
+		return nil, err
+	}
+	if err := checkKubernetesNames("secrets", sortedKeys(project.Secrets)); err != nil {
+		return nil, err
+	}
+
+	err = project.ForEachService(options.Services, func(name string, service *types.ServiceConfig) error {
+		return export.service(*service)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := export.write(options.Output); err != nil {
+		return nil, err
+	}
+	sort.Strings(export.warnings)
+	return export.warnings, nil
+}
+
+// kubernetesExport converts a compose project into Kubernetes resources, grouped by the file they are written to
+type kubernetesExport struct {
+	project *types.Project
+	files   map[string][]any
+	// project volumes, configs and secrets already declared
+	volumes  map[string]bool
+	configs  map[string]bool
+	secrets  map[string]bool
+	warnings []string
+}
+
+func (k *kubernetesExport) warn(service string, format string, args ...any) {
+	k.warnings = append(k.warnings, fmt.Sprintf("service %q: %s", service, fmt.Sprintf(format, args...)))
+}
+
+func (k *kubernetesExport) add(file string, resource any) {
+	k.files[file] = append(k.files[file], resource)
+}
+
+// write saves resources as multi-documents YAML files in dir
+func (k *kubernetesExport) write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for file, resources := range k.files {
+		var buf bytes.Buffer
+		for i, r := range resources {
+			if i > 0 {
+				buf.WriteString("---\n")
+			}
+			b, err := manifestYAML(r)
+			if err != nil {
+				return err
+			}
+			buf.Write(b)
+		}
+		mode := os.FileMode(0o644)
+		if file == exportSecretsFile {
+			mode = 0o600
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), buf.Bytes(), mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manifestYAML marshals a Kubernetes resource, without status nor empty attributes the API types can't omit
+func manifestYAML(resource any) ([]byte, error) {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var manifest map[string]any
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+	delete(manifest, "status")
+	return yaml.Marshal(pruneEmpty(manifest))
+}
+
+func pruneEmpty(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, e := range value {
+			e = pruneEmpty(e)
+			if m, ok := e.(map[string]any); e == nil || ok && len(m) == 0 {
+				delete(value, k)
+				continue
+			}
+			value[k] = e
+		}
+	case []any:
+		for i, e := range value {
+			value[i] = pruneEmpty(e)
+		}
+	}
+	return v
+}
+
+var invalidKubernetesName = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// kubernetesName converts a compose name into a valid Kubernetes resource name
+func kubernetesName(name string) string {
+	return strings.Trim(invalidKubernetesName.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// checkKubernetesNames rejects distinct compose names converted into the same Kubernetes name, as exported
+// resources would overwrite each other
+func checkKubernetesNames(kind string, names []string) error {
+	sort.Strings(names)
+	seen := map[string]string{}
+	for _, name := range names {
+		converted := kubernetesName(name)
+		if other, ok := seen[converted]; ok {
+			return fmt.Errorf("%s %q and %q both convert to Kubernetes name %q, rename one of them to export project", kind, other, name, converted)
+		}
+		seen[converted] = name
+	}
+	return nil
+}
+
+func (k *kubernetesExport) labels(service string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":    kubernetesName(service),
+		"app.kubernetes.io/part-of": kubernetesName(k.project.Name),
+	}
+}
+
+func (k *kubernetesExport) objectMeta(name string, labels map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:   kubernetesName(name),
+		Labels: labels,
+	}
+}
+
+func (k *kubernetesExport) service(service types.ServiceConfig) error {
+	name := service.Name
+	labels := k.labels(name)
+	k.warnUnsupported(service)
+
+	container := k.container(service)
+	pod := corev1.PodSpec{
+		Hostname:       service.Hostname,
+		InitContainers: k.initContainers(service),
+		Containers:     []corev1.Container{container},
+		HostAliases:    hostAliases(service.ExtraHosts),
+	}
+	stateful, err := k.podVolumes(service, &pod)
+	if err != nil {
+		return err
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      labels,
+			Annotations: service.Labels,
+		},
+		Spec: pod,
+	}
+	replicas := int32(1)
+	if service.Deploy != nil && service.Deploy.Replicas != nil {
+		replicas = int32(*service.Deploy.Replicas)
+	} else if service.Scale != nil {
+		replicas = int32(*service.Scale)
+	}
+	switch service.Restart {
+	case "", types.RestartPolicyAlways, types.RestartPolicyUnlessStopped:
+	default:
+		k.warn(name, "restart policy %q can't be exported, Kubernetes always restarts workload containers", service.Restart)
+	}
+
+	file := kubernetesName(name) + ".yaml"
+	if stateful {
+		if replicas > 1 {
+			k.warn(name, "%d replicas share the same persistent volume claims", replicas)
+		}
+		k.add(file, appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			ObjectMeta: k.objectMeta(name, labels),
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:    &replicas,
+				ServiceName: kubernetesName(name),
+				Selector:    &metav1.LabelSelector{MatchLabels: labels},
+				Template:    template,
+			},
+		})
+	} else {
+		k.add(file, appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: k.objectMeta(name, labels),
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: template,
+			},
+		})
+	}
+
+	if ports := k.servicePorts(service); len(ports) > 0 {
+		k.add(file, corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: k.objectMeta(name, labels),
+			Spec: corev1.ServiceSpec{
+				Selector: labels,
+				Ports:    ports,
+			},
+		})
+	}
+	return nil
+}
+
+// warnUnsupported reports service attributes which have no equivalent in a Kubernetes workload
+func (k *kubernetesExport) warnUnsupported(service types.ServiceConfig) {
+	name := service.Name
+	unsupported := map[string]bool{
+		"devices":      len(service.Devices) > 0,
+		"volumes_from": len(service.VolumesFrom) > 0,
+		"pid":          service.Pid != "",
+		"ipc":          service.Ipc != "",
+		"links":        len(service.Links) > 0,
+		"dns":          len(service.DNS) > 0,
+		"sysctls":      len(service.Sysctls) > 0,
+		"ulimits":      len(service.Ulimits) > 0,
+		"logging":      service.Logging != nil,
+	}
+	for attribute, set := range unsupported {
+		if set {
+			k.warn(name, "%s can't be exported", attribute)
+		}
+	}
+	if service.NetworkMode != "" {
+		k.warn(name, "network_mode %q can't be exported, pods use cluster network", service.NetworkMode)
+	}
+	for network := range service.Networks {
+		if network != "default" {
+			k.warn(name, "network %q can't be exported, pods use cluster network", network)
+		}
+	}
+	if service.Build != nil {
+		k.warn(name, "image is built by compose, and must be pushed to a registry the cluster can pull from")
+	}
+	if len(service.Ports) > 0 {
+		k.warn(name, "published ports are only exposed inside cluster by a ClusterIP service, use an ingress or a load balancer to expose them")
+	}
+}
+
+func (k *kubernetesExport) container(service types.ServiceConfig) corev1.Container {
+	name := service.Name
+	container := corev1.Container{
+		Name:       kubernetesName(name),
+		Image:      api.GetImageNameOrDefault(service, k.project.Name),
+		Command:    service.Entrypoint,
+		Args:       service.Command,
+		WorkingDir: service.WorkingDir,
+		TTY:        service.Tty,
+		Stdin:      service.StdinOpen,
+	}
+
+	for _, variable := range sortedKeys(service.Environment) {
+		value := service.Environment[variable]
+		if value == nil {
+			k.warn(name, "environment variable %s has no value, and is ignored", variable)
+			continue
+		}
+		container.Env = append(container.Env, corev1.EnvVar{Name: variable, Value: *value})
+	}
+
+	for _, port := range service.Ports {
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			ContainerPort: int32(port.Target),
+			Protocol:      kubernetesProtocol(port.Protocol),
+		})
+	}
+
+	container.Resources = k.resources(service)
+	probe := k.probe(service)
+	container.LivenessProbe = probe
+	container.ReadinessProbe = probe
+	container.SecurityContext = k.securityContext(service)
+	return container
+}
+
+func kubernetesProtocol(protocol string) corev1.Protocol {
+	if protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return corev1.Protocol(strings.ToUpper(protocol))
+}
+
+func (k *kubernetesExport) resources(service types.ServiceConfig) corev1.ResourceRequirements {
+	var requirements corev1.ResourceRequirements
+	convert := func(r *types.Resource) corev1.ResourceList {
+		if r == nil {
+			return nil
+		}
+		if len(r.Devices) > 0 || len(r.GenericResources) > 0 || r.Pids > 0 {
+			k.warn(service.Name, "devices, generic resources and pids resources can't be exported")
+		}
+		list := corev1.ResourceList{}
+		if r.NanoCPUs > 0 {
+			list[corev1.ResourceCPU] = *resource.NewMilliQuantity(int64(r.NanoCPUs*1000), resource.DecimalSI)
+		}
+		if r.MemoryBytes > 0 {
+			list[corev1.ResourceMemory] = *resource.NewQuantity(int64(r.MemoryBytes), resource.BinarySI)
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		return list
+	}
+	if service.Deploy != nil {
+		requirements.Limits = convert(service.Deploy.Resources.Limits)
+		requirements.Requests = convert(service.Deploy.Resources.Reservations)
+	}
+	if requirements.Limits == nil && (service.CPUS > 0 || service.MemLimit > 0) {
+		requirements.Limits = convert(&types.Resource{NanoCPUs: types.NanoCPUs(service.CPUS), MemoryBytes: service.MemLimit})
+	}
+	return requirements
+}
+
+// probe converts service healthcheck into a probe, used both to restart a failing container and to check readiness
+func (k *kubernetesExport) probe(service types.ServiceConfig) *corev1.Probe {
+	check := service.HealthCheck
+	if check == nil || check.Disable {
+		return nil
+	}
+	var command []string
+	switch {
+	case len(check.Test) == 0:
+		k.warn(service.Name, "healthcheck defined by image can't be exported")
+		return nil
+	case check.Test[0] == "NONE":
+		return nil
+	case check.Test[0] == "CMD":
+		command = check.Test[1:]
+	case check.Test[0] == "CMD-SHELL":
+		command = []string{"/bin/sh", "-c", strings.Join(check.Test[1:], " ")}
+	default:
+		command = check.Test
+	}
+
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: command},
+		},
+		TimeoutSeconds:      durationSeconds(check.Timeout),
+		PeriodSeconds:       durationSeconds(check.Interval),
+		InitialDelaySeconds: durationSeconds(check.StartPeriod),
+	}
+	if check.Retries != nil {
+		probe.FailureThreshold = int32(*check.Retries)
+	}
+	return probe
+}
+
+// durationSeconds converts a duration into seconds, as used by probes, zero meaning Kubernetes default
+func durationSeconds(d *types.Duration) int32 {
+	if d == nil || *d <= 0 {
+		return 0
+	}
+	seconds := int32(time.Duration(*d).Round(time.Second).Seconds())
+	if seconds == 0 {
+		return 1
+	}
+	return seconds
+}
+
+func (k *kubernetesExport) securityContext(service types.ServiceConfig) *corev1.SecurityContext {
+	var sc corev1.SecurityContext
+	var set bool
+	if service.Privileged {
+		sc.Privileged = &service.Privileged
+		set = true
+	}
+	if service.ReadOnly {
+		sc.ReadOnlyRootFilesystem = &service.ReadOnly
+		set = true
+	}
+	if len(service.CapAdd) > 0 || len(service.CapDrop) > 0 {
+		sc.Capabilities = &corev1.Capabilities{}
+		for _, c := range service.CapAdd {
+			sc.Capabilities.Add = append(sc.Capabilities.Add, corev1.Capability(c))
+		}
+		for _, c := range service.CapDrop {
+			sc.Capabilities.Drop = append(sc.Capabilities.Drop, corev1.Capability(c))
+		}
+		set = true
+	}
+	if service.User != "" {
+		user, group, _ := strings.Cut(service.User, ":")
+		uid, err := strconv.ParseInt(user, 10, 64)
+		if err != nil {
+			k.warn(service.Name, "user %q can't be exported, only numeric user IDs are supported", service.User)
+		} else {
+			sc.RunAsUser = &uid
+			set = true
+		}
+		if gid, err := strconv.ParseInt(group, 10, 64); err == nil {
+			sc.RunAsGroup = &gid
+		}
+	}
+	if !set {
+		return nil
+	}
+	return &sc
+}
+
+// initContainers converts depends_on into init containers waiting for dependencies to be reachable
+func (k *kubernetesExport) initContainers(service types.ServiceConfig) []corev1.Container {
+	var containers []corev1.Container
+	for _, dependency := range sortedKeys(service.DependsOn) {
+		condition := service.DependsOn[dependency].Condition
+		if condition == types.ServiceConditionCompletedSuccessfully {
+			k.warn(service.Name, "dependency on %s completing successfully can't be exported", dependency)
+			continue
+		}
+		dep, err := k.project.GetService(dependency)
+		if err != nil {
+			continue
+		}
+		ports := k.servicePorts(dep)
+		if len(ports) == 0 {
+			k.warn(service.Name, "dependency on %s can't be exported, as it doesn't expose any port", dependency)
+			continue
+		}
+		host := kubernetesName(dependency)
+		containers = append(containers, corev1.Container{
+			Name:    "wait-for-" + host,
+			Image:   exportWaitImage,
+			Command: []string{"/bin/sh", "-c", fmt.Sprintf("until nc -z %s %d; do echo waiting for %s; sleep 2; done", host, ports[0].Port, host)},
+		})
+	}
+	return containers
+}
+
+// servicePorts lists ports published or exposed by service, to be declared by a Kubernetes service
+func (k *kubernetesExport) servicePorts(service types.ServiceConfig) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+	seen := map[string]bool{}
+	add := func(port int32, target int32, protocol corev1.Protocol) {
+		name := fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port)
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		ports = append(ports, corev1.ServicePort{
+			Name:       name,
+			Port:       port,
+			TargetPort: intstr.FromInt32(target),
+			Protocol:   protocol,
+		})
+	}
+	for _, p := range service.Ports {
+		port := int32(p.Target)
+		if published, err := strconv.ParseInt(p.Published, 10, 32); err == nil {
+			port = int32(published)
+		}
+		add(port, int32(p.Target), kubernetesProtocol(p.Protocol))
+	}
+	for _, e := range service.Expose {
+		port, protocol, _ := strings.Cut(e, "/")
+		target, err := strconv.ParseInt(port, 10, 32)
+		if err != nil {
+			continue
+		}
+		add(int32(target), int32(target), kubernetesProtocol(protocol))
+	}
+	return ports
+}
+
+func hostAliases(hosts types.HostsList) []corev1.HostAlias {
+	byIP := map[string][]string{}
+	for host, ips := range hosts {
+		for _, ip := range ips {
+			byIP[ip] = append(byIP[ip], host)
+		}
+	}
+	var aliases []corev1.HostAlias
+	for _, ip := range sortedKeys(byIP) {
+		hostnames := byIP[ip]
+		sort.Strings(hostnames)
+		aliases = append(aliases, corev1.HostAlias{IP: ip, Hostnames: hostnames})
+	}
+	return aliases
+}
+
+// podVolumes converts service volumes, configs and secrets into pod volumes, and tells if service uses persistent storage
+func (k *kubernetesExport) podVolumes(service types.ServiceConfig, pod *corev1.PodSpec) (bool, error) {
+	name := service.Name
+	container := &pod.Containers[0]
+	var stateful bool
+	for i, v := range service.Volumes {
+		volume := corev1.Volume{Name: fmt.Sprintf("volume-%d", i)}
+		switch {
+		case v.Type == types.VolumeTypeVolume && v.Source != "":
+			claim := k.persistentVolumeClaim(name, v.Source)
+			volume.Name = claim
+			volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim, ReadOnly: v.ReadOnly}
+			stateful = true
+		case v.Type == types.VolumeTypeVolume:
+			volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+		case v.Type == types.VolumeTypeTmpfs:
+			volume.EmptyDir = &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}
+		default:
+			k.warn(name, "%s mount of %s can't be exported", v.Type, v.Source)
+			continue
+		}
+		pod.Volumes = append(pod.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: v.Target,
+			ReadOnly:  v.ReadOnly,
+		})
+	}
+	for i, target := range service.Tmpfs {
+		volume := corev1.Volume{
+			Name:         fmt.Sprintf("tmpfs-%d", i),
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		}
+		pod.Volumes = append(pod.Volumes, volume)
+		path, _, _ := strings.Cut(target, ":")
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: volume.Name, MountPath: path})
+	}
+
+	for _, c := range service.Configs {
+		ref := types.FileReferenceConfig(c)
+		if ref.Target == "" {
+			ref.Target = "/" + ref.Source
+		}
+		configMap, err := k.configMap(name, ref.Source)
+		if err != nil {
+			return false, err
+		}
+		volume, mount := fileVolume("config-"+configMap, ref)
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+			Items:                []corev1.KeyToPath{{Key: fileKey(ref.Source), Path: fileKey(ref.Source), Mode: fileMode(ref)}},
+		}
+		k.warnFileOwnership(name, ref)
+		pod.Volumes = append(pod.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+	}
+
+	for _, s := range service.Secrets {
+		ref := types.FileReferenceConfig(s)
+		switch {
+		case ref.Target == "":
+			ref.Target = "/run/secrets/" + ref.Source
+		case !filepath.IsAbs(ref.Target):
+			ref.Target = "/run/secrets/" + ref.Target
+		}
+		secret, err := k.secret(name, ref.Source)
+		if err != nil {
+			return false, err
+		}
+		volume, mount := fileVolume("secret-"+secret, ref)
+		volume.Secret = &corev1.SecretVolumeSource{
+			SecretName: secret,
+			Items:      []corev1.KeyToPath{{Key: fileKey(ref.Source), Path: fileKey(ref.Source), Mode: fileMode(ref)}},
+		}
+		k.warnFileOwnership(name, ref)
+		pod.Volumes = append(pod.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+	}
+	return stateful, nil
+}
+
+// fileVolume returns a volume mounting a single file from a config map or secret at target path
+func fileVolume(name string, ref types.FileReferenceConfig) (corev1.Volume, corev1.VolumeMount) {
+	return corev1.Volume{Name: name}, corev1.VolumeMount{
+		Name:      name,
+		MountPath: ref.Target,
+		SubPath:   fileKey(ref.Source),
+		ReadOnly:  true,
+	}
+}
+
+var invalidFileKey = regexp.MustCompile(`[^-._a-zA-Z0-9]+`)
+
+// fileKey returns the key a config or secret content is stored by in a config map or secret
+func fileKey(name string) string {
+	return invalidFileKey.ReplaceAllString(name, "-")
+}
+
+func fileMode(ref types.FileReferenceConfig) *int32 {
+	if ref.Mode == nil {
+		return nil
+	}
+	mode := int32(*ref.Mode)
+	return &mode
+}
+
+func (k *kubernetesExport) warnFileOwnership(service string, ref types.FileReferenceConfig) {
+	if ref.UID != "" || ref.GID != "" {
+		k.warn(service, "uid and gid of %s can't be exported", ref.Source)
+	}
+}
+
+// persistentVolumeClaim declares a claim for a project named volume, once, and returns its name
+func (k *kubernetesExport) persistentVolumeClaim(service string, name string) string {
+	claim := kubernetesName(name)
+	if k.volumes[name] {
+		return claim
+	}
+	k.volumes[name] = true
+
+	volume := k.project.Volumes[name]
+	if volume.External {
+		k.warn(service, "volume %s is external, a persistent volume claim named %s must exist in cluster", name, claim)
+		return claim
+	}
+	if volume.Driver != "" || len(volume.DriverOpts) > 0 {
+		k.warn(service, "driver and driver options of volume %s can't be exported", name)
+	}
+	k.add(exportVolumesFile, corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: k.objectMeta(name, map[string]string{"app.kubernetes.io/part-of": kubernetesName(k.project.Name)}),
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(exportVolumeSize)},
+			},
+		},
+	})
+	return claim
+}
+
+// configMap declares a config map for a project config, once, and returns its name
+func (k *kubernetesExport) configMap(service string, name string) (string, error) {
+	configMap := kubernetesName(name)
+	if k.configs[name] {
+		return configMap, nil
+	}
+	k.configs[name] = true
+
+	config := types.FileObjectConfig(k.project.Configs[name])
+	if config.External {
+		k.warn(service, "config %s is external, a config map named %s must exist in cluster", name, configMap)
+		return configMap, nil
+	}
+	content, err := k.fileObjectContent(config)
+	if err != nil {
+		return "", fmt.Errorf("config %s: %w", name, err)
+	}
+	k.add(exportConfigsFile, corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: k.objectMeta(name, map[string]string{"app.kubernetes.io/part-of": kubernetesName(k.project.Name)}),
+		Data:       map[string]string{fileKey(name): string(content)},
+	})
+	return configMap, nil
+}
+
+// secret declares a secret for a project secret, once, and returns its name
+func (k *kubernetesExport) secret(service string, name string) (string, error) {
+	secret := kubernetesName(name)
+	if k.secrets[name] {
+		return secret, nil
+	}
+	k.secrets[name] = true
+
+	config := types.FileObjectConfig(k.project.Secrets[name])
+	if config.External {
+		k.warn(service, "secret %s is external, a secret named %s must exist in cluster", name, secret)
+		return secret, nil
+	}
+	content, err := k.fileObjectContent(config)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", name, err)
+	}
+	k.add(exportSecretsFile, corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: k.objectMeta(name, map[string]string{"app.kubernetes.io/part-of": kubernetesName(k.project.Name)}),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{fileKey(name): content},
+	})
+	return secret, nil
+}
+
+func (k *kubernetesExport) fileObjectContent(config types.FileObjectConfig) ([]byte, error) {
+	switch {
+	case config.File != "":
+		return os.ReadFile(config.File)
+	case config.Environment != "":
+		return []byte(k.project.Environment[config.Environment]), nil
+	default:
+		return []byte(config.Content), nil
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,195 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestExport(t *testing.T) {
+	retries := uint64(3)
+	interval := types.Duration(10 * time.Second)
+	project := &types.Project{
+		Name: "export_test",
+		Services: types.Services{
+			"web": {
+				Name:  "web",
+				Image: "nginx:1.25",
+				Ports: []types.ServicePortConfig{{Target: 80, Published: "8080", Protocol: "tcp"}},
+				DependsOn: types.DependsOnConfig{
+					"db":    {Condition: types.ServiceConditionHealthy},
+					"setup": {Condition: types.ServiceConditionCompletedSuccessfully},
+				},
+				Deploy: &types.DeployConfig{
+					Resources: types.Resources{
+						Limits: &types.Resource{NanoCPUs: 0.5, MemoryBytes: 256 * 1024 * 1024},
+					},
+				},
+				Restart: types.RestartPolicyNo,
+			},
+			"db": {
+				Name:   "db",
+				Image:  "postgres:16",
+				Expose: types.StringOrNumberList{"5432"},
+				HealthCheck: &types.HealthCheckConfig{
+					Test:     types.HealthCheckTest{"CMD", "pg_isready"},
+					Interval: &interval,
+					Retries:  &retries,
+				},
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeVolume, Source: "db_data", Target: "/var/lib/postgresql/data"},
+					{Type: types.VolumeTypeBind, Source: "/srv/init", Target: "/docker-entrypoint-initdb.d"},
+				},
+				Secrets: []types.ServiceSecretConfig{{Source: "password"}},
+			},
+			"setup": {
+				Name:  "setup",
+				Image: "alpine",
+			},
+		},
+		Volumes: types.Volumes{
+			"db_data": {Name: "export_test_db_data"},
+		},
+		Secrets: types.Secrets{
+			"password": {Content: "s3cr3t"},
+		},
+	}
+
+	dir := t.TempDir()
+	tested := composeService{}
+	warnings, err := tested.Export(context.Background(), project, api.ExportOptions{Output: dir})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, warnings, []string{
+		`service "db": bind mount of /srv/init can't be exported`,
+		`service "web": dependency on setup completing successfully can't be exported`,
+		`service "web": published ports are only exposed inside cluster by a ClusterIP service, use an ingress or a load balancer to expose them`,
+		`service "web": restart policy "no" can't be exported, Kubernetes always restarts workload containers`,
+	})
+
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	var files []string
+	for _, e := range entries {
+		files = append(files, e.Name())
+	}
+	assert.DeepEqual(t, files, []string{"_secrets.yaml", "_volumes.yaml", "db.yaml", "setup.yaml", "web.yaml"})
+
+	documents := readManifests(t, filepath.Join(dir, "web.yaml"))
+	assert.Equal(t, len(documents), 2)
+	var deployment appsv1.Deployment
+	assert.NilError(t, yaml.Unmarshal([]byte(documents[0]), &deployment))
+	assert.Equal(t, deployment.Kind, "Deployment")
+	pod := deployment.Spec.Template.Spec
+	assert.Equal(t, len(pod.InitContainers), 1)
+	assert.DeepEqual(t, pod.InitContainers[0].Command, []string{"/bin/sh", "-c", "until nc -z db 5432; do echo waiting for db; sleep 2; done"})
+	assert.Equal(t, pod.Containers[0].Resources.Limits.Cpu().String(), "500m")
+	assert.Equal(t, pod.Containers[0].Resources.Limits.Memory().String(), "256Mi")
+
+	var service corev1.Service
+	assert.NilError(t, yaml.Unmarshal([]byte(documents[1]), &service))
+	assert.Equal(t, service.Spec.Ports[0].Port, int32(8080))
+	assert.Equal(t, service.Spec.Ports[0].TargetPort.IntValue(), 80)
+
+	documents = readManifests(t, filepath.Join(dir, "db.yaml"))
+	var statefulSet appsv1.StatefulSet
+	assert.NilError(t, yaml.Unmarshal([]byte(documents[0]), &statefulSet))
+	assert.Equal(t, statefulSet.Kind, "StatefulSet")
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	assert.DeepEqual(t, container.LivenessProbe.Exec.Command, []string{"pg_isready"})
+	assert.Equal(t, container.LivenessProbe.PeriodSeconds, int32(10))
+	assert.Equal(t, container.LivenessProbe.FailureThreshold, int32(3))
+	assert.Equal(t, len(container.VolumeMounts), 2)
+	assert.Equal(t, container.VolumeMounts[0].Name, "db-data")
+	assert.Equal(t, container.VolumeMounts[1].MountPath, "/run/secrets/password")
+
+	var secret corev1.Secret
+	assert.NilError(t, yaml.Unmarshal([]byte(readManifests(t, filepath.Join(dir, exportSecretsFile))[0]), &secret))
+	assert.Equal(t, string(secret.Data["password"]), "s3cr3t")
+}
+
+func TestExportFileNames(t *testing.T) {
+	project := &types.Project{
+		Name: "export_test",
+		Services: types.Services{
+			"secrets": {
+				Name:    "secrets",
+				Image:   "vault:1.13",
+				Secrets: []types.ServiceSecretConfig{{Source: "token"}},
+			},
+		},
+		Secrets: types.Secrets{
+			"token": {Name: "token", Content: "s3cr3t"},
+		},
+	}
+	dir := t.TempDir()
+	_, err := (&composeService{}).Export(context.Background(), project, api.ExportOptions{Output: dir})
+	assert.NilError(t, err)
+
+	documents := readManifests(t, filepath.Join(dir, "secrets.yaml"))
+	var deployment appsv1.Deployment
+	assert.NilError(t, yaml.Unmarshal([]byte(documents[0]), &deployment))
+	assert.Equal(t, deployment.Kind, "Deployment")
+	info, err := os.Stat(filepath.Join(dir, "secrets.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0o644))
+
+	info, err = os.Stat(filepath.Join(dir, exportSecretsFile))
+	assert.NilError(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0o600))
+}
+
+func TestExportNameCollision(t *testing.T) {
+	project := &types.Project{
+		Name: "export_test",
+		Services: types.Services{
+			"web_app": {Name: "web_app", Image: "nginx:1.25"},
+			"web-app": {Name: "web-app", Image: "nginx:1.25"},
+		},
+	}
+	_, err := (&composeService{}).Export(context.Background(), project, api.ExportOptions{Output: t.TempDir()})
+	assert.Error(t, err, `services "web-app" and "web_app" both convert to Kubernetes name "web-app", rename one of them to export project`)
+
+	_, err = (&composeService{}).Export(context.Background(), project, api.ExportOptions{Output: t.TempDir(), Services: []string{"web_app"}})
+	assert.NilError(t, err)
+
+	project.Services = types.Services{"web": {Name: "web", Image: "nginx:1.25"}}
+	project.Configs = types.Configs{
+		"app_conf": {Name: "app_conf", Content: "a"},
+		"app.conf": {Name: "app.conf", Content: "b"},
+	}
+	_, err = (&composeService{}).Export(context.Background(), project, api.ExportOptions{Output: t.TempDir()})
+	assert.ErrorContains(t, err, `configs "app.conf" and "app_conf" both convert to Kubernetes name "app-conf"`)
+}
+
+func readManifests(t *testing.T, file string) []string {
+	content, err := os.ReadFile(file)
+	assert.NilError(t, err)
+	return strings.Split(string(content), "---\n")
+}
@@ -18,9 +18,17 @@ package compose
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/builder/remotecontext/urlutil"
 	"github.com/opencontainers/go-digest"
+	"golang.org/x/exp/maps"
 )
 
 // ServiceHash computes the configuration hash for a service.
@@ -39,3 +47,91 @@ func ServiceHash(o types.ServiceConfig) (string, error) {
 	}
 	return digest.SHA256.FromBytes(bytes).Encoded(), nil
 }
+
+// BuildHash computes a fingerprint of the inputs used to build a service image: build configuration,
+// resolved build args, Dockerfile and local build contexts content, honouring `.dockerignore`.
+// An empty hash is returned when build relies on a remote context, which can't be fingerprinted.
+func BuildHash(service types.ServiceConfig, args types.MappingWithEquals) (string, error) {
+	return buildHash(service, args, nil)
+}
+
+// buildHash computes BuildHash, also passing files of the main build context to visit when set
+func buildHash(service types.ServiceConfig, args types.MappingWithEquals, visit func(path string, rel string, info fs.FileInfo) error) (string, error) {
+	if service.Build == nil || isRemoteContext(service.Build.Context) {
+		return "", nil
+	}
+	h := digest.SHA256.Digester()
+	config, err := json.Marshal(service.Build)
+	if err != nil {
+		return "", err
+	}
+	h.Hash().Write(config)
+	resolved, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	h.Hash().Write(resolved)
+
+	if service.Build.DockerfileInline == "" {
+		content, err := readDockerfile(service.Build)
+		if err != nil {
+			return "", err
+		}
+		h.Hash().Write(content)
+	}
+
+	contexts := []string{service.Build.Context}
+	names := maps.Keys(service.Build.AdditionalContexts)
+	sort.Strings(names)
+	for _, name := range names {
+		path := service.Build.AdditionalContexts[name]
+		if !isRemoteContext(path) && !strings.Contains(path, "://") && !strings.HasPrefix(path, serviceContextPrefix) {
+			contexts = append(contexts, path)
+		}
+	}
+	for i, dir := range contexts {
+		var v func(path string, rel string, info fs.FileInfo) error
+		if i == 0 {
+			v = visit
+		}
+		if err := hashBuildContext(h.Hash(), dir, v); err != nil {
+			return "", err
+		}
+	}
+	return h.Digest().Encoded(), nil
+}
+
+func isRemoteContext(context string) bool {
+	return urlutil.IsGitURL(context) || urlutil.IsURL(context)
+}
+
+// hashBuildContext writes the content of files in a build context to hash, skipping those excluded by `.dockerignore`.
+// Files are also passed to visit when set
+func hashBuildContext(h io.Writer, dir string, visit func(path string, rel string, info fs.FileInfo) error) error {
+	return walkBuildContext(dir, func(path string, rel string, info fs.FileInfo) error {
+		if visit != nil {
+			if err := visit(path, rel, info); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(h, "%s\x00%o\x00%d\x00", rel, info.Mode(), info.Size())
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(h, target)
+			return err
+		case info.Mode().IsRegular():
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close() //nolint:errcheck
+			_, err = io.Copy(h, f)
+			return err
+		}
+		return nil
+	})
+}
@@ -17,6 +17,8 @@
 package compose
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -31,6 +33,39 @@ func TestServiceHash(t *testing.T) {
 	assert.Equal(t, hash1, hash2)
 }
 
+func TestBuildHash(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("Dockerfile", "FROM alpine")
+	write(".dockerignore", "*.log")
+	write("main.go", "package main")
+	write("debug.log", "some logs")
+
+	service := types.ServiceConfig{Name: "app", Build: &types.BuildConfig{Context: dir}}
+	hash, err := BuildHash(service, nil)
+	assert.NilError(t, err)
+
+	write("debug.log", "more logs")
+	unchanged, err := BuildHash(service, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, hash, unchanged, "ignored files must not change build hash")
+
+	withArgs, err := BuildHash(service, types.NewMappingWithEquals([]string{"VERSION=2"}))
+	assert.NilError(t, err)
+	assert.Assert(t, hash != withArgs)
+
+	write("main.go", "package main // changed")
+	changed, err := BuildHash(service, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, hash != changed)
+
+	remote, err := BuildHash(types.ServiceConfig{Build: &types.BuildConfig{Context: "https://github.com/docker/compose.git"}}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, remote, "")
+}
+
 func serviceConfig(replicas int) types.ServiceConfig {
 	return types.ServiceConfig{
 		Scale: &replicas,
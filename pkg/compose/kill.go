@@ -65,14 +65,13 @@ func (s *composeService) kill(ctx context.Context, projectName string, options a
 	containers.
 		forEach(func(container moby.Container) {
 			eg.Go(func() error {
-				eventName := getContainerProgressName(container)
-				w.Event(progress.KillingEvent(eventName))
+				notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleKilling))
 				err := s.apiClient().ContainerKill(ctx, container.ID, options.Signal)
 				if err != nil {
-					w.Event(progress.ErrorMessageEvent(eventName, "Error while Killing"))
+					notifyContainersLifecycle(ctx, w, Containers{container}, api.LifecycleFailed, "Error while Killing", err)
 					return err
 				}
-				w.Event(progress.KilledEvent(eventName))
+				notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleKilled))
 				return nil
 			})
 		})
@@ -0,0 +1,113 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	moby "github.com/docker/docker/api/types"
+)
+
+// containerLifecycleEvent creates a LifecycleEvent about container, using compose labels to identify project and service
+func containerLifecycleEvent(container moby.Container, action api.LifecycleAction) api.LifecycleEvent {
+	number, _ := strconv.Atoi(container.Labels[api.ContainerNumberLabel])
+	return api.LifecycleEvent{
+		Project:     container.Labels[api.ProjectLabel],
+		Service:     container.Labels[api.ServiceLabel],
+		Container:   getCanonicalContainerName(container),
+		ContainerID: container.ID,
+		Number:      number,
+		Action:      action,
+	}
+}
+
+// notifyLifecycle sends event to the listeners registered on context, and renders it on progress writer
+func notifyLifecycle(ctx context.Context, w progress.Writer, event api.LifecycleEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	for _, listener := range api.LifecycleListeners(ctx) {
+		listener(event)
+	}
+	w.Event(toProgressEvent(event))
+}
+
+// notifyContainersLifecycle notifies the same action for a set of containers. err is only set for LifecycleFailed
+func notifyContainersLifecycle(ctx context.Context, w progress.Writer, containers Containers, action api.LifecycleAction, reason string, err error) {
+	for _, container := range containers {
+		event := containerLifecycleEvent(container, action)
+		event.Reason = reason
+		event.Error = err
+		notifyLifecycle(ctx, w, event)
+	}
+}
+
+func toProgressEvent(event api.LifecycleEvent) progress.Event {
+	id := "Container " + event.Container
+	switch event.Action {
+	case api.LifecycleCreating:
+		return progress.CreatingEvent(id)
+	case api.LifecycleCreated:
+		return progress.CreatedEvent(id)
+	case api.LifecycleRecreating:
+		return progress.NewEvent(id, progress.Working, "Recreate")
+	case api.LifecycleRecreated:
+		return progress.NewEvent(id, progress.Done, "Recreated")
+	case api.LifecycleStarting:
+		return progress.StartingEvent(id)
+	case api.LifecycleStarted:
+		return progress.StartedEvent(id)
+	case api.LifecycleRunning:
+		return progress.RunningEvent(id)
+	case api.LifecycleRestarting:
+		return progress.RestartingEvent(id)
+	case api.LifecycleRestarted:
+		// a restarted container is reported as started, as it always has been by restart command
+		return progress.StartedEvent(id)
+	case api.LifecycleWaiting:
+		return progress.Waiting(id)
+	case api.LifecycleHealthy:
+		return progress.Healthy(id)
+	case api.LifecycleExited:
+		return progress.Exited(id)
+	case api.LifecycleStopping:
+		return progress.StoppingEvent(id)
+	case api.LifecycleStopped:
+		return progress.StoppedEvent(id)
+	case api.LifecycleKilling:
+		return progress.KillingEvent(id)
+	case api.LifecycleKilled:
+		return progress.KilledEvent(id)
+	case api.LifecycleRemoving:
+		return progress.RemovingEvent(id)
+	case api.LifecycleRemoved:
+		return progress.RemovedEvent(id)
+	case api.LifecycleSkipped:
+		return progress.SkippedEvent(id, event.Reason)
+	case api.LifecycleFailed:
+		if event.Reason != "" {
+			return progress.ErrorMessageEvent(id, event.Reason)
+		}
+		return progress.ErrorEvent(id)
+	default:
+		return progress.NewEvent(id, progress.Working, string(event.Action))
+	}
+}
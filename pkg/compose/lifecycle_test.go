@@ -0,0 +1,155 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	moby "github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+type recordingWriter struct {
+	events []progress.Event
+}
+
+func (r *recordingWriter) Start(context.Context) error { return nil }
+func (r *recordingWriter) Stop()                       {}
+func (r *recordingWriter) Event(e progress.Event)      { r.events = append(r.events, e) }
+func (r *recordingWriter) Events(events []progress.Event) {
+	r.events = append(r.events, events...)
+}
+func (r *recordingWriter) TailMsgf(string, ...interface{}) {}
+
+func TestNotifyLifecycle(t *testing.T) {
+	var received []api.LifecycleEvent
+	ctx := api.WithLifecycleListener(context.Background(), func(event api.LifecycleEvent) {
+		received = append(received, event)
+	})
+	w := &recordingWriter{}
+
+	container := moby.Container{
+		ID:    "123",
+		Names: []string{"/test-web-2"},
+		Labels: map[string]string{
+			api.ProjectLabel:         "test",
+			api.ServiceLabel:         "web",
+			api.ContainerNumberLabel: "2",
+		},
+	}
+	notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleHealthy))
+	notifyContainersLifecycle(ctx, w, Containers{container}, api.LifecycleSkipped, "optional", nil)
+
+	assert.Equal(t, len(received), 2)
+	assert.Equal(t, received[0].Project, "test")
+	assert.Equal(t, received[0].Service, "web")
+	assert.Equal(t, received[0].Container, "test-web-2")
+	assert.Equal(t, received[0].ContainerID, "123")
+	assert.Equal(t, received[0].Number, 2)
+	assert.Equal(t, received[0].Action, api.LifecycleHealthy)
+	assert.Assert(t, !received[0].Timestamp.IsZero())
+	assert.Equal(t, received[1].Reason, "optional")
+
+	assert.Equal(t, len(w.events), 2)
+	assert.Equal(t, w.events[0], progress.Healthy("Container test-web-2"))
+	assert.Equal(t, w.events[1], progress.SkippedEvent("Container test-web-2", "optional"))
+}
+
+func TestNotifyLifecycleWithoutListener(t *testing.T) {
+	w := &recordingWriter{}
+	notifyLifecycle(context.Background(), w, api.LifecycleEvent{Container: "test", Action: api.LifecycleFailed, Reason: "boom"})
+	assert.Equal(t, len(w.events), 1)
+	assert.Equal(t, w.events[0], progress.ErrorMessageEvent("Container test", "boom"))
+}
+
+func TestNotifyLifecycleSerializesListeners(t *testing.T) {
+	var received []api.LifecycleEvent
+	ctx := api.WithLifecycleListener(context.Background(), func(event api.LifecycleEvent) {
+		received = append(received, event)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			notifyLifecycle(ctx, progress.ContextWriter(ctx), api.LifecycleEvent{Container: "test", Action: api.LifecycleStarted})
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, len(received), 50)
+}
+
+func TestNotifyLifecycleSlowListenerDoesNotBlockOthers(t *testing.T) {
+	unblock := make(chan struct{})
+	blocked := make(chan struct{})
+	slow := api.WithLifecycleListener(context.Background(), func(event api.LifecycleEvent) {
+		close(blocked)
+		<-unblock
+	})
+	go notifyLifecycle(slow, progress.ContextWriter(slow), api.LifecycleEvent{Container: "slow", Action: api.LifecycleStarted})
+	<-blocked
+
+	var received []api.LifecycleEvent
+	ctx := api.WithLifecycleListener(context.Background(), func(event api.LifecycleEvent) {
+		received = append(received, event)
+	})
+	notifyLifecycle(ctx, progress.ContextWriter(ctx), api.LifecycleEvent{Container: "test", Action: api.LifecycleStarted})
+	close(unblock)
+	assert.Equal(t, len(received), 1)
+}
+
+func TestRemoveLifecycle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	removeErr := errors.New("container is in use")
+	apiClient.EXPECT().ContainerRemove(gomock.Any(), "123", containerType.RemoveOptions{}).Return(nil)
+	apiClient.EXPECT().ContainerRemove(gomock.Any(), "456", containerType.RemoveOptions{}).Return(removeErr)
+
+	var mtx sync.Mutex
+	received := map[string][]api.LifecycleEvent{}
+	ctx := api.WithLifecycleListener(context.Background(), func(event api.LifecycleEvent) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		received[event.ContainerID] = append(received[event.ContainerID], event)
+	})
+	err := tested.remove(ctx, Containers{
+		testContainer("service1", "123", false),
+		testContainer("service2", "456", false),
+	}, api.RemoveOptions{})
+	assert.Equal(t, err, removeErr)
+
+	assert.Equal(t, len(received["123"]), 2)
+	assert.Equal(t, received["123"][0].Action, api.LifecycleRemoving)
+	assert.Equal(t, received["123"][1].Action, api.LifecycleRemoved)
+	assert.Equal(t, len(received["456"]), 2)
+	assert.Equal(t, received["456"][1].Action, api.LifecycleFailed)
+	assert.Equal(t, received["456"][1].Reason, "Error while Removing")
+	assert.Equal(t, received["456"][1].Error, removeErr)
+}
@@ -0,0 +1,304 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// LintIgnoreExtension lists rules not to apply to a service, or to the whole project when set at top level
+const LintIgnoreExtension = "x-lint-ignore"
+
+type lintRule struct {
+	api.LintRule
+	check api.LintCheck
+}
+
+// lintRulesMutex guards lintRules against rules registered concurrently with a Lint call
+var lintRulesMutex sync.RWMutex
+
+var lintRules = []lintRule{
+	{
+		LintRule: api.LintRule{
+			ID:          "image-latest",
+			Description: "Service image has no tag, or uses the mutable latest tag",
+			Severity:    api.LintSeverityWarning,
+		},
+		check: checkImageTag,
+	},
+	{
+		LintRule: api.LintRule{
+			ID:          "privileged",
+			Description: "Service runs a privileged container",
+			Severity:    api.LintSeverityWarning,
+		},
+		check: func(_ *types.Project, service types.ServiceConfig) []string {
+			if service.Privileged {
+				return []string{"container runs in privileged mode"}
+			}
+			return nil
+		},
+	},
+	{
+		LintRule: api.LintRule{
+			ID:          "host-network",
+			Description: "Service uses host network mode",
+			Severity:    api.LintSeverityWarning,
+		},
+		check: func(_ *types.Project, service types.ServiceConfig) []string {
+			if service.NetworkMode == "host" {
+				return []string{"container shares host network stack"}
+			}
+			return nil
+		},
+	},
+	{
+		LintRule: api.LintRule{
+			ID:          "port-all-interfaces",
+			Description: "Service publishes a port on all network interfaces",
+			Severity:    api.LintSeverityNote,
+		},
+		check: checkPortsHostIP,
+	},
+	{
+		LintRule: api.LintRule{
+			ID:          "healthcheck-missing",
+			Description: "Service has no healthcheck while other services depend on it being healthy",
+			Severity:    api.LintSeverityError,
+		},
+		check: checkHealthcheckDependency,
+	},
+	{
+		LintRule: api.LintRule{
+			ID:          "docker-socket",
+			Description: "Service mounts the docker engine socket",
+			Severity:    api.LintSeverityError,
+		},
+		check: checkDockerSocket,
+	},
+	{
+		LintRule: api.LintRule{
+			ID:          "secret-in-environment",
+			Description: "Service passes a secret as an environment variable, rather than as a secret",
+			Severity:    api.LintSeverityWarning,
+		},
+		check: checkSecretEnvironment,
+	},
+}
+
+// LintRules returns the rules applied by Lint
+func LintRules() []api.LintRule {
+	registered := registeredLintRules()
+	rules := make([]api.LintRule, len(registered))
+	for i, r := range registered {
+		rules[i] = r.LintRule
+	}
+	return rules
+}
+
+// RegisterLintRule adds a rule applied by Lint, which runs check against each service
+func RegisterLintRule(rule api.LintRule, check api.LintCheck) error {
+	if rule.ID == "" || check == nil {
+		return errors.New("lint rule requires an ID and a check")
+	}
+	if !isLintSeverity(rule.Severity) {
+		return fmt.Errorf("invalid severity %q for lint rule %s", rule.Severity, rule.ID)
+	}
+	lintRulesMutex.Lock()
+	defer lintRulesMutex.Unlock()
+	if isLintRule(lintRules, rule.ID) {
+		return fmt.Errorf("lint rule %q is already registered", rule.ID)
+	}
+	lintRules = append(lintRules, lintRule{LintRule: rule, check: check})
+	return nil
+}
+
+func registeredLintRules() []lintRule {
+	lintRulesMutex.RLock()
+	defer lintRulesMutex.RUnlock()
+	return append([]lintRule{}, lintRules...)
+}
+
+func (s *composeService) Lint(_ context.Context, project *types.Project, options api.LintOptions) ([]api.LintFinding, error) {
+	rules := registeredLintRules()
+	for id, severity := range options.Severity {
+		if !isLintRule(rules, id) {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+		if !isLintSeverity(severity) {
+			return nil, fmt.Errorf("invalid severity %q for lint rule %s", severity, id)
+		}
+	}
+
+	projectIgnored := lintIgnored(project.Extensions)
+	findings := []api.LintFinding{}
+	err := project.ForEachService(options.Services, func(name string, service *types.ServiceConfig) error {
+		ignored := lintIgnored(service.Extensions)
+		for _, rule := range rules {
+			severity := rule.Severity
+			if s, ok := options.Severity[rule.ID]; ok {
+				severity = s
+			}
+			if severity == api.LintSeverityOff || projectIgnored[rule.ID] || ignored[rule.ID] {
+				continue
+			}
+			for _, message := range rule.check(project, *service) {
+				findings = append(findings, api.LintFinding{
+					Rule:     rule.ID,
+					Severity: severity,
+					Service:  name,
+					Message:  message,
+				})
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Service < findings[j].Service
+	})
+	return findings, err
+}
+
+func isLintRule(rules []lintRule, id string) bool {
+	for _, r := range rules {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func isLintSeverity(severity api.LintSeverity) bool {
+	switch severity {
+	case api.LintSeverityError, api.LintSeverityWarning, api.LintSeverityNote, api.LintSeverityOff:
+		return true
+	default:
+		return false
+	}
+}
+
+// lintIgnored returns rules listed by x-lint-ignore extension, either as a single rule ID or a list
+func lintIgnored(extensions types.Extensions) map[string]bool {
+	ignored := map[string]bool{}
+	switch v := extensions[LintIgnoreExtension].(type) {
+	case string:
+		ignored[v] = true
+	case []any:
+		for _, id := range v {
+			if s, ok := id.(string); ok {
+				ignored[s] = true
+			}
+		}
+	}
+	return ignored
+}
+
+func checkImageTag(_ *types.Project, service types.ServiceConfig) []string {
+	if service.Image == "" || service.Build != nil {
+		return nil
+	}
+	named, err := reference.ParseNormalizedNamed(service.Image)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid image reference %q", service.Image)}
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return nil
+	}
+	tagged, ok := named.(reference.Tagged)
+	switch {
+	case !ok:
+		return []string{fmt.Sprintf("image %s has no tag, and implicitly uses latest", service.Image)}
+	case tagged.Tag() == "latest":
+		return []string{fmt.Sprintf("image %s uses latest tag", service.Image)}
+	}
+	return nil
+}
+
+func checkPortsHostIP(_ *types.Project, service types.ServiceConfig) []string {
+	var messages []string
+	for _, port := range service.Ports {
+		switch port.HostIP {
+		case "", "0.0.0.0", "::":
+			published := port.Published
+			if published == "" {
+				published = "a random port"
+			}
+			messages = append(messages, fmt.Sprintf("port %d published as %s on all network interfaces, set a host IP to restrict access", port.Target, published))
+		}
+	}
+	return messages
+}
+
+func checkHealthcheckDependency(project *types.Project, service types.ServiceConfig) []string {
+	if service.HealthCheck != nil && !service.HealthCheck.Disable {
+		return nil
+	}
+	var dependents []string
+	for _, s := range project.Services {
+		if d, ok := s.DependsOn[service.Name]; ok && d.Condition == types.ServiceConditionHealthy {
+			dependents = append(dependents, s.Name)
+		}
+	}
+	if len(dependents) == 0 {
+		return nil
+	}
+	sort.Strings(dependents)
+	return []string{fmt.Sprintf("no healthcheck is defined, but service_healthy condition is required by %s", strings.Join(dependents, ", "))}
+}
+
+var dockerSockets = map[string]bool{
+	"/var/run/docker.sock":     true,
+	"/run/docker.sock":         true,
+	`\\.\pipe\docker_engine`:   true,
+	"//./pipe/docker_engine":   true,
+	"/var/run/docker.sock.raw": true,
+}
+
+func checkDockerSocket(_ *types.Project, service types.ServiceConfig) []string {
+	var messages []string
+	for _, v := range service.Volumes {
+		if (v.Type == types.VolumeTypeBind || v.Type == types.VolumeTypeNamedPipe) && dockerSockets[v.Source] {
+			messages = append(messages, fmt.Sprintf("%s is mounted, which grants control of docker engine", v.Source))
+		}
+	}
+	return messages
+}
+
+func checkSecretEnvironment(_ *types.Project, service types.ServiceConfig) []string {
+	var messages []string
+	for name, value := range service.Environment {
+		// conventional variables set to a secret file path, as supported by many official images
+		if strings.HasSuffix(strings.ToUpper(name), "_FILE") {
+			continue
+		}
+		if value != nil && *value != "" && secretVariableName.MatchString(name) {
+			messages = append(messages, fmt.Sprintf("environment variable %s looks like a secret, use secrets instead", name))
+		}
+	}
+	sort.Strings(messages)
+	return messages
+}
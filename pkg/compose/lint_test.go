@@ -0,0 +1,164 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func lintTestProject() *types.Project {
+	return &types.Project{
+		Name: "lint-test",
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Image: "myapp",
+				Ports: []types.ServicePortConfig{
+					{Target: 8080, Published: "80"},
+					{Target: 8443, Published: "443", HostIP: "127.0.0.1"},
+				},
+				Environment: types.MappingWithEquals{
+					"DB_PASSWORD":      ptr("s3cr3t"),
+					"DB_PASSWORD_FILE": ptr("/run/secrets/db"),
+					"DEBUG":            ptr("true"),
+				},
+				DependsOn: types.DependsOnConfig{
+					"db": {Condition: types.ServiceConditionHealthy},
+				},
+			},
+			"db": {
+				Name:       "db",
+				Image:      "postgres:16",
+				Privileged: true,
+				Extensions: types.Extensions{
+					LintIgnoreExtension: []any{"privileged"},
+				},
+			},
+			"proxy": {
+				Name:        "proxy",
+				Image:       "traefik:latest",
+				NetworkMode: "host",
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeBind, Source: "/var/run/docker.sock", Target: "/var/run/docker.sock"},
+				},
+			},
+			"built": {
+				Name:  "built",
+				Image: "built",
+				Build: &types.BuildConfig{Context: "."},
+			},
+		},
+	}
+}
+
+func TestLint(t *testing.T) {
+	tested := composeService{}
+	findings, err := tested.Lint(context.Background(), lintTestProject(), api.LintOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, findings, []api.LintFinding{
+		{Rule: "image-latest", Severity: api.LintSeverityWarning, Service: "app", Message: "image myapp has no tag, and implicitly uses latest"},
+		{Rule: "port-all-interfaces", Severity: api.LintSeverityNote, Service: "app", Message: "port 8080 published as 80 on all network interfaces, set a host IP to restrict access"},
+		{Rule: "secret-in-environment", Severity: api.LintSeverityWarning, Service: "app", Message: "environment variable DB_PASSWORD looks like a secret, use secrets instead"},
+		{Rule: "healthcheck-missing", Severity: api.LintSeverityError, Service: "db", Message: "no healthcheck is defined, but service_healthy condition is required by app"},
+		{Rule: "image-latest", Severity: api.LintSeverityWarning, Service: "proxy", Message: "image traefik:latest uses latest tag"},
+		{Rule: "host-network", Severity: api.LintSeverityWarning, Service: "proxy", Message: "container shares host network stack"},
+		{Rule: "docker-socket", Severity: api.LintSeverityError, Service: "proxy", Message: "/var/run/docker.sock is mounted, which grants control of docker engine"},
+	})
+}
+
+func TestLintOptions(t *testing.T) {
+	tested := composeService{}
+	project := lintTestProject()
+	project.Extensions = types.Extensions{LintIgnoreExtension: "docker-socket"}
+
+	findings, err := tested.Lint(context.Background(), project, api.LintOptions{
+		Services: []string{"proxy"},
+		Severity: map[string]api.LintSeverity{
+			"image-latest": api.LintSeverityOff,
+			"host-network": api.LintSeverityError,
+		},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, findings, []api.LintFinding{
+		{Rule: "host-network", Severity: api.LintSeverityError, Service: "proxy", Message: "container shares host network stack"},
+	})
+
+	_, err = tested.Lint(context.Background(), project, api.LintOptions{
+		Severity: map[string]api.LintSeverity{"no-such-rule": api.LintSeverityError},
+	})
+	assert.Error(t, err, `unknown lint rule "no-such-rule"`)
+}
+
+func TestRegisterLintRule(t *testing.T) {
+	registered := registeredLintRules()
+	t.Cleanup(func() {
+		lintRulesMutex.Lock()
+		lintRules = registered
+		lintRulesMutex.Unlock()
+	})
+
+	rule := api.LintRule{
+		ID:          "restart-missing",
+		Description: "Service has no restart policy",
+		Severity:    api.LintSeverityNote,
+	}
+	err := RegisterLintRule(rule, func(_ *types.Project, service types.ServiceConfig) []string {
+		if service.Restart == "" {
+			return []string{"no restart policy is set"}
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, isLintRule(registeredLintRules(), "restart-missing"))
+	assert.DeepEqual(t, LintRules()[len(LintRules())-1], rule)
+
+	err = RegisterLintRule(rule, func(*types.Project, types.ServiceConfig) []string { return nil })
+	assert.Error(t, err, `lint rule "restart-missing" is already registered`)
+	err = RegisterLintRule(api.LintRule{ID: "other", Severity: "fatal"}, func(*types.Project, types.ServiceConfig) []string { return nil })
+	assert.Error(t, err, `invalid severity "fatal" for lint rule other`)
+	err = RegisterLintRule(api.LintRule{ID: "other", Severity: api.LintSeverityNote}, nil)
+	assert.Error(t, err, "lint rule requires an ID and a check")
+
+	tested := composeService{}
+	project := &types.Project{
+		Services: types.Services{
+			"web": {Name: "web", Image: "nginx:1.25", Restart: "always"},
+			"db":  {Name: "db", Image: "postgres:16"},
+		},
+	}
+	findings, err := tested.Lint(context.Background(), project, api.LintOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, findings, []api.LintFinding{
+		{Rule: "restart-missing", Severity: api.LintSeverityNote, Service: "db", Message: "no restart policy is set"},
+	})
+
+	findings, err = tested.Lint(context.Background(), project, api.LintOptions{
+		Severity: map[string]api.LintSeverity{"restart-missing": api.LintSeverityOff},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 0)
+}
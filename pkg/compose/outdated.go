@@ -0,0 +1,127 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/docker/errdefs"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/sync/errgroup"
+)
+
+func (s *composeService) Outdated(ctx context.Context, project *types.Project, options api.OutdatedOptions) ([]api.ImageUpdate, error) {
+	resolver := ImageDigestResolver(ctx, s.configFile(), s.apiClient())
+
+	var checked []types.ServiceConfig
+	err := project.ForEachService(options.Services, func(name string, service *types.ServiceConfig) error {
+		if service.Image == "" || service.Build != nil {
+			return nil
+		}
+		// only report images `compose pull` would update
+		switch service.PullPolicy {
+		case types.PullPolicyNever, types.PullPolicyBuild, types.PullPolicyMissing, types.PullPolicyIfNotPresent:
+			return nil
+		}
+		checked = append(checked, *service)
+		return nil
+	}, types.IgnoreDependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make([]api.ImageUpdate, len(checked))
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(s.maxConcurrency)
+	for i, service := range checked {
+		i, service := i, service
+		eg.Go(func() error {
+			update, err := s.checkImageUpdate(ctx, service, resolver)
+			updates[i] = update
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].Service < updates[j].Service
+	})
+	return updates, nil
+}
+
+func (s *composeService) checkImageUpdate(ctx context.Context, service types.ServiceConfig, resolver func(reference.Named) (digest.Digest, error)) (api.ImageUpdate, error) {
+	update := api.ImageUpdate{
+		Service: service.Name,
+		Image:   service.Image,
+	}
+	named, err := reference.ParseDockerRef(service.Image)
+	if err != nil {
+		return update, err
+	}
+
+	inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, service.Image)
+	switch {
+	case errdefs.IsNotFound(err):
+		update.Status = api.ImageMissing
+	case err != nil:
+		return update, err
+	default:
+		update.LocalDigest = repoDigest(named, inspect.RepoDigests)
+	}
+
+	if canonical, ok := named.(reference.Canonical); ok {
+		// image is pinned, registry can't offer another one
+		update.RemoteDigest = canonical.Digest().String()
+	} else {
+		remote, err := resolver(named)
+		if err != nil {
+			update.Status = api.ImageUnknown
+			update.Error = err.Error()
+			return update, nil
+		}
+		update.RemoteDigest = remote.String()
+	}
+
+	if update.Status == api.ImageMissing {
+		return update, nil
+	}
+	if update.LocalDigest == update.RemoteDigest {
+		update.Status = api.ImageUpToDate
+	} else {
+		update.Status = api.ImageOutdated
+	}
+	return update, nil
+}
+
+// repoDigest selects the digest image was pulled by from named repository
+func repoDigest(named reference.Named, repoDigests []string) string {
+	for _, rd := range repoDigests {
+		ref, err := reference.ParseNormalizedNamed(rd)
+		if err != nil {
+			continue
+		}
+		if canonical, ok := ref.(reference.Canonical); ok && ref.Name() == named.Name() {
+			return canonical.Digest().String()
+		}
+	}
+	return ""
+}
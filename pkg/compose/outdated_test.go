@@ -0,0 +1,94 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+const (
+	localDigest  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	remoteDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func TestOutdated(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli:      cli,
+		maxConcurrency: -1,
+	}
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"current":  {Name: "current", Image: "nginx"},
+			"outdated": {Name: "outdated", Image: "redis:7"},
+			"missing":  {Name: "missing", Image: "alpine"},
+			"broken":   {Name: "broken", Image: "private/image"},
+			"built":    {Name: "built", Image: "app", Build: &types.BuildConfig{Context: "."}},
+			"never":    {Name: "never", Image: "busybox", PullPolicy: types.PullPolicyNever},
+		},
+	}
+
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "nginx").
+		Return(moby.ImageInspect{RepoDigests: []string{"nginx@" + localDigest}}, nil, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "redis:7").
+		Return(moby.ImageInspect{RepoDigests: []string{"redis@" + localDigest}}, nil, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "alpine").
+		Return(moby.ImageInspect{}, nil, errdefs.NotFound(errors.New("no such image")))
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "private/image").
+		Return(moby.ImageInspect{}, nil, nil)
+
+	distribution := func(d string) registry.DistributionInspect {
+		return registry.DistributionInspect{Descriptor: ocispec.Descriptor{Digest: digest.Digest(d)}}
+	}
+	apiClient.EXPECT().DistributionInspect(gomock.Any(), "docker.io/library/nginx:latest", gomock.Any()).
+		Return(distribution(localDigest), nil)
+	apiClient.EXPECT().DistributionInspect(gomock.Any(), "docker.io/library/redis:7", gomock.Any()).
+		Return(distribution(remoteDigest), nil)
+	apiClient.EXPECT().DistributionInspect(gomock.Any(), "docker.io/library/alpine:latest", gomock.Any()).
+		Return(distribution(remoteDigest), nil)
+	apiClient.EXPECT().DistributionInspect(gomock.Any(), "docker.io/private/image:latest", gomock.Any()).
+		Return(registry.DistributionInspect{}, errors.New("unauthorized"))
+
+	updates, err := tested.Outdated(context.Background(), project, api.OutdatedOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, updates, []api.ImageUpdate{
+		{Service: "broken", Image: "private/image", Status: api.ImageUnknown, Error: "unauthorized"},
+		{Service: "current", Image: "nginx", LocalDigest: localDigest, RemoteDigest: localDigest, Status: api.ImageUpToDate},
+		{Service: "missing", Image: "alpine", RemoteDigest: remoteDigest, Status: api.ImageMissing},
+		{Service: "outdated", Image: "redis:7", LocalDigest: localDigest, RemoteDigest: remoteDigest, Status: api.ImageOutdated},
+	})
+}
@@ -18,7 +18,13 @@ package compose
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/distribution/reference"
@@ -26,16 +32,24 @@ import (
 	"github.com/docker/compose/v2/internal/ocipush"
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/progress"
+	"github.com/docker/compose/v2/pkg/prompt"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
 func (s *composeService) Publish(ctx context.Context, project *types.Project, repository string, options api.PublishOptions) error {
+	files, err := s.collectPublishedFiles(project, options)
+	if err != nil {
+		return err
+	}
 	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
-		return s.publish(ctx, project, repository, options)
+		return s.publish(ctx, project, repository, files, options)
 	}, s.stdinfo(), "Publishing")
 }
 
-func (s *composeService) publish(ctx context.Context, project *types.Project, repository string, options api.PublishOptions) error {
-	err := s.Push(ctx, project, api.PushOptions{})
+func (s *composeService) publish(ctx context.Context, project *types.Project, repository string, files []publishedFile, options api.PublishOptions) error {
+	err := s.pushImages(ctx, project, options)
 	if err != nil {
 		return err
 	}
@@ -50,16 +64,17 @@ func (s *composeService) publish(ctx context.Context, project *types.Project, re
 	})
 
 	var layers []ocipush.Pushable
-	for _, file := range project.ComposeFiles {
-		f, err := os.ReadFile(file)
-		if err != nil {
-			return err
+	for _, file := range files {
+		var layerDescriptor v1.Descriptor
+		if file.mediaType == ocipush.ComposeYAMLMediaType {
+			layerDescriptor = ocipush.DescriptorForComposeFile(file.path, file.data)
+			layerDescriptor.Annotations[ocipush.ComposePathAnnotation] = filepath.ToSlash(file.path)
+		} else {
+			layerDescriptor = ocipush.DescriptorForResource(file.mediaType, file.path, file.data)
 		}
-
-		layerDescriptor := ocipush.DescriptorForComposeFile(file, f)
 		layers = append(layers, ocipush.Pushable{
 			Descriptor: layerDescriptor,
-			Data:       f,
+			Data:       file.data,
 		})
 	}
 
@@ -83,11 +98,7 @@ func (s *composeService) publish(ctx context.Context, project *types.Project, re
 		Status: progress.Working,
 	})
 	if !s.dryRun {
-		err = ocipush.PushManifest(ctx, resolver, named, layers, options.OCIVersion)
-		if err != nil {
-			return err
-		}
-
+		ociVersion, reason, err := ocipush.PushManifest(ctx, resolver, named, layers, options.OCIVersion)
 		if err != nil {
 			w.Event(progress.Event{
 				ID:     repository,
@@ -96,6 +107,12 @@ func (s *composeService) publish(ctx context.Context, project *types.Project, re
 			})
 			return err
 		}
+		w.Event(progress.Event{
+			ID:         "OCI version",
+			Text:       string(ociVersion),
+			StatusText: reason,
+			Status:     progress.Done,
+		})
 	}
 	w.Event(progress.Event{
 		ID:     repository,
@@ -105,6 +122,173 @@ func (s *composeService) publish(ctx context.Context, project *types.Project, re
 	return nil
 }
 
+// publishedFile is a local file the project depends on, published as an OCI layer
+type publishedFile struct {
+	// path relative to project directory
+	path      string
+	mediaType string
+	data      []byte
+}
+
+// collectPublishedFiles reads compose files and, with WithResources, all other local files the model depends on:
+// included and extended compose files, env files and file-based configs. User is asked to confirm when some look
+// like they contain secrets.
+func (s *composeService) collectPublishedFiles(project *types.Project, options api.PublishOptions) ([]publishedFile, error) {
+	var (
+		files   []publishedFile
+		skipped []string
+		seen    = map[string]bool{}
+	)
+	add := func(file string, mediaType string) error {
+		rel, err := projectRelativePath(project.WorkingDir, file)
+		if err != nil {
+			return fmt.Errorf("cannot publish project: %w", err)
+		}
+		if seen[rel] {
+			return nil
+		}
+		seen[rel] = true
+		if mediaType != ocipush.ComposeYAMLMediaType && !options.WithResources {
+			skipped = append(skipped, rel)
+			return nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files = append(files, publishedFile{path: rel, mediaType: mediaType, data: data})
+		return nil
+	}
+
+	for _, file := range project.ComposeFiles {
+		if err := add(file, ocipush.ComposeYAMLMediaType); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range options.ModelFiles {
+		if err := add(file, ocipush.ComposeResourceMediaType); err != nil {
+			return nil, err
+		}
+	}
+	services := project.AllServices()
+	names := maps.Keys(services)
+	sort.Strings(names)
+	for _, name := range names {
+		for _, envFile := range services[name].EnvFiles {
+			if _, err := os.Stat(envFile.Path); err != nil && !envFile.Required {
+				continue
+			}
+			if err := add(envFile.Path, ocipush.ComposeEnvFileMediaType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	names = maps.Keys(project.Configs)
+	sort.Strings(names)
+	for _, name := range names {
+		if file := project.Configs[name].File; file != "" {
+			if err := add(file, ocipush.ComposeResourceMediaType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	names = maps.Keys(project.Secrets)
+	sort.Strings(names)
+	for _, name := range names {
+		if project.Secrets[name].File != "" {
+			logrus.Warnf("secret %q is a local file, which is not published", name)
+		}
+	}
+	if len(skipped) > 0 {
+		logrus.Warnf("project depends on local files which are not published: %s. Use --with-resources to publish them", strings.Join(skipped, ", "))
+	}
+
+	var suspicious []string
+	for _, file := range files {
+		if reason := secretLike(file.path, file.mediaType, file.data); reason != "" {
+			suspicious = append(suspicious, fmt.Sprintf("  %s: %s", file.path, reason))
+		}
+	}
+	if len(suspicious) == 0 {
+		return files, nil
+	}
+	msg := fmt.Sprintf("Some project files look like they contain secrets:\n%s\nAre you sure you want to publish them?", strings.Join(suspicious, "\n"))
+	if options.AssumeYes {
+		logrus.Warn(msg)
+		return files, nil
+	}
+	confirm, err := prompt.NewPrompt(s.stdin(), s.stdout()).Confirm(msg, false)
+	if err != nil {
+		return nil, err
+	}
+	if !confirm {
+		return nil, errors.New("publish canceled, as project files look like they contain secrets")
+	}
+	return files, nil
+}
+
+var (
+	privateKeyPattern    = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	envAssignmentPattern = regexp.MustCompile(`^\s*(export\s+)?([A-Za-z0-9_]+)\s*=\s*\S`)
+	secretFileExtensions = []string{".pem", ".key", ".p12", ".pfx"}
+	// secretVariableName matches names of variables holding a secret, used by publish and lint
+	secretVariableName = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIALS?)`)
+)
+
+// secretLike tells why a file looks like it contains a secret, or returns an empty string
+func secretLike(path string, mediaType string, data []byte) string {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, "id_rsa") || strings.HasPrefix(name, "id_ecdsa") || strings.HasPrefix(name, "id_ed25519") {
+		return "SSH key file"
+	}
+	for _, ext := range secretFileExtensions {
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			return "key or certificate file"
+		}
+	}
+	if privateKeyPattern.Match(data) {
+		return "contains a private key"
+	}
+	if mediaType == ocipush.ComposeEnvFileMediaType {
+		for _, line := range strings.Split(string(data), "\n") {
+			if m := envAssignmentPattern.FindStringSubmatch(line); m != nil && secretVariableName.MatchString(m[2]) {
+				return fmt.Sprintf("sets %s", m[2])
+			}
+		}
+	}
+	return ""
+}
+
+// pushImages pushes service images. Images for services with attestations are built and pushed by BuildKit,
+// as pushing a local image from the engine would not include them
+func (s *composeService) pushImages(ctx context.Context, project *types.Project, options api.PublishOptions) error {
+	var attested []string
+	for name, service := range project.Services {
+		if service.Build == nil || service.Image == "" {
+			continue
+		}
+		attests, err := serviceAttestations(service, options.Attestations)
+		if err != nil {
+			return err
+		}
+		if hasAttestations(attests) {
+			attested = append(attested, name)
+		}
+	}
+	if len(attested) > 0 {
+		_, err := s.build(ctx, project, api.BuildOptions{
+			Push:         true,
+			Services:     attested,
+			Attestations: options.Attestations,
+			Progress:     progress.Mode,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return s.Push(ctx, project.WithServicesDisabled(attested...), api.PushOptions{})
+}
+
 func (s *composeService) generateImageDigestsOverride(ctx context.Context, project *types.Project) ([]byte, error) {
 	project, err := project.WithProfiles([]string{"*"})
 	if err != nil {
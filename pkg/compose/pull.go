@@ -23,7 +23,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/distribution/reference"
@@ -31,10 +33,12 @@ import (
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/registry"
 	"github.com/hashicorp/go-multierror"
 	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/docker/compose/v2/pkg/api"
@@ -77,7 +81,11 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 			continue
 		}
 
-		switch service.PullPolicy {
+		policy, err := servicePullPolicy(service)
+		if err != nil {
+			return err
+		}
+		switch policy {
 		case types.PullPolicyNever, types.PullPolicyBuild:
 			w.Event(progress.Event{
 				ID:     name,
@@ -94,6 +102,19 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 				})
 				continue
 			}
+		default:
+			fresh, err := s.imagePulledWithinPolicy(ctx, service, images)
+			if err != nil {
+				return err
+			}
+			if fresh {
+				w.Event(progress.Event{
+					ID:     name,
+					Status: progress.Done,
+					Text:   "Skipped - Image was pulled recently",
+				})
+				continue
+			}
 		}
 
 		if service.Build != nil && opts.IgnoreBuildable {
@@ -156,6 +177,102 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 	return multierror.Append(nil, pullErrors...).ErrorOrNil()
 }
 
+const (
+	// PullPolicyExtension sets a time-based pull policy from compose file, as `pull_policy` only accepts
+	// values defined by the compose specification
+	PullPolicyExtension = "x-pull-policy"
+	// pullPolicyDaily pulls image when local one is older than a day
+	pullPolicyDaily = "daily"
+	// pullPolicyWeekly pulls image when local one is older than a week
+	pullPolicyWeekly = "weekly"
+	// pullPolicyEveryPrefix sets a custom interval, like `every_12h` or `every_3d`
+	pullPolicyEveryPrefix = "every_"
+)
+
+// PullInterval returns the maximum age of a local image set by a time-based pull policy
+func PullInterval(policy string) (time.Duration, bool, error) {
+	switch policy {
+	case pullPolicyDaily:
+		return 24 * time.Hour, true, nil
+	case pullPolicyWeekly:
+		return 7 * 24 * time.Hour, true, nil
+	}
+	value, ok := strings.CutPrefix(policy, pullPolicyEveryPrefix)
+	if !ok {
+		return 0, false, nil
+	}
+	interval, err := parsePullInterval(value)
+	if err != nil || interval <= 0 {
+		return 0, false, fmt.Errorf("invalid pull_policy %q: interval must be a positive duration", policy)
+	}
+	return interval, true, nil
+}
+
+// parsePullInterval parses a duration, also accepting days (`d`) and weeks (`w`) units
+func parsePullInterval(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			i, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(i) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+// servicePullPolicy returns service pull policy, using PullPolicyExtension when pull_policy is not set
+func servicePullPolicy(service types.ServiceConfig) (string, error) {
+	x, ok := service.Extensions[PullPolicyExtension]
+	if !ok || service.PullPolicy != "" {
+		return service.PullPolicy, nil
+	}
+	policy, isString := x.(string)
+	if !isString {
+		return "", fmt.Errorf("service %q: %s must be a string", service.Name, PullPolicyExtension)
+	}
+	if _, ok, err := PullInterval(policy); err != nil || !ok {
+		return "", fmt.Errorf("service %q: %s only supports daily, weekly and every_<duration>, got %q", service.Name, PullPolicyExtension, policy)
+	}
+	return policy, nil
+}
+
+// imagePulledWithinPolicy tells if service uses a time-based pull policy and local image is recent enough
+// to not be pulled again
+func (s *composeService) imagePulledWithinPolicy(ctx context.Context, service types.ServiceConfig, localImages map[string]string) (bool, error) {
+	policy, err := servicePullPolicy(service)
+	if err != nil {
+		return false, err
+	}
+	interval, ok, err := PullInterval(policy)
+	if err != nil || !ok {
+		return false, err
+	}
+	if _, ok := localImages[service.Image]; !ok {
+		return false, nil
+	}
+	inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, service.Image)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	// engine doesn't track pull time, fall back to creation time for images compose didn't pull
+	pulled, ok, err := s.lastPulled(inspect.ID)
+	if err != nil {
+		logrus.Debugf("failed to read pull records: %v", err)
+	}
+	if !ok {
+		pulled, err = time.Parse(time.RFC3339Nano, inspect.Created)
+		if err != nil {
+			return false, nil
+		}
+	}
+	return time.Since(pulled) < interval, nil
+}
+
 func imageAlreadyPresent(serviceImage string, localImages map[string]string) bool {
 	normalizedImage, err := reference.ParseDockerRef(serviceImage)
 	if err != nil {
@@ -199,9 +316,32 @@ func (s *composeService) pullServiceImage(ctx context.Context, service types.Ser
 		platform = defaultPlatform
 	}
 
-	stream, err := s.apiClient().ImagePull(ctx, service.Image, image.PullOptions{
-		RegistryAuth: encodedAuth,
-		Platform:     platform,
+	err = s.withRegistryRetry(ctx, ref, service.Name, w, func() error {
+		stream, err := s.apiClient().ImagePull(ctx, service.Image, image.PullOptions{
+			RegistryAuth: encodedAuth,
+			Platform:     platform,
+		})
+		if err != nil {
+			return err
+		}
+		defer stream.Close() //nolint:errcheck
+
+		dec := json.NewDecoder(stream)
+		for {
+			var jm jsonmessage.JSONMessage
+			if err := dec.Decode(&jm); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			if jm.Error != nil {
+				return errors.New(jm.Error.Message)
+			}
+			if !quietPull {
+				toPullProgressEvent(service.Name, jm, w)
+			}
+		}
 	})
 
 	// check if has error and the service has a build section
@@ -226,22 +366,6 @@ func (s *composeService) pullServiceImage(ctx context.Context, service types.Ser
 		return "", WrapCategorisedComposeError(err, PullFailure)
 	}
 
-	dec := json.NewDecoder(stream)
-	for {
-		var jm jsonmessage.JSONMessage
-		if err := dec.Decode(&jm); err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			return "", WrapCategorisedComposeError(err, PullFailure)
-		}
-		if jm.Error != nil {
-			return "", WrapCategorisedComposeError(errors.New(jm.Error.Message), PullFailure)
-		}
-		if !quietPull {
-			toPullProgressEvent(service.Name, jm, w)
-		}
-	}
 	w.Event(progress.Event{
 		ID:     service.Name,
 		Status: progress.Done,
@@ -252,6 +376,9 @@ func (s *composeService) pullServiceImage(ctx context.Context, service types.Ser
 	if err != nil {
 		return "", err
 	}
+	if err := s.recordPull(inspected.ID, time.Now()); err != nil {
+		logrus.Debugf("failed to record pull of %s: %v", service.Image, err)
+	}
 	return inspected.ID, nil
 }
 
@@ -295,7 +422,11 @@ func (s *composeService) pullRequiredImages(ctx context.Context, project *types.
 		if service.Image == "" {
 			continue
 		}
-		switch service.PullPolicy {
+		policy, err := servicePullPolicy(service)
+		if err != nil {
+			return err
+		}
+		switch policy {
 		case "", types.PullPolicyMissing, types.PullPolicyIfNotPresent:
 			if _, ok := images[service.Image]; ok {
 				continue
@@ -304,6 +435,14 @@ func (s *composeService) pullRequiredImages(ctx context.Context, project *types.
 			continue
 		case types.PullPolicyAlways:
 			// force pull
+		default:
+			fresh, err := s.imagePulledWithinPolicy(ctx, service, images)
+			if err != nil {
+				return err
+			}
+			if fresh {
+				continue
+			}
 		}
 		needPull = append(needPull, service)
 	}
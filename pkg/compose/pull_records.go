@@ -0,0 +1,94 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+// pullRecordsRetention is the duration a pull is recorded for, so that records of images not pulled anymore get dropped
+const pullRecordsRetention = 365 * 24 * time.Hour
+
+// pullRecordsMutex serializes updates to pull records, as images are pulled concurrently
+var pullRecordsMutex sync.Mutex
+
+// pullRecordsPath returns the file recording when images were pulled by compose, keyed by image ID, as the
+// engine doesn't track it. This one is stored next to the docker CLI config file
+func (s *composeService) pullRecordsPath() string {
+	return filepath.Join(filepath.Dir(s.configFile().Filename), "compose", "pulls.json")
+}
+
+func readPullRecords(path string) (map[string]time.Time, error) {
+	records := map[string]time.Time{}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// recordPull records image with ID was pulled at given time
+func (s *composeService) recordPull(id string, pulled time.Time) error {
+	pullRecordsMutex.Lock()
+	defer pullRecordsMutex.Unlock()
+
+	path := s.pullRecordsPath()
+	records, err := readPullRecords(path)
+	if err != nil {
+		// corrupted records are reset
+		records = map[string]time.Time{}
+	}
+	records[id] = pulled
+	for k, t := range records {
+		if pulled.Sub(t) > pullRecordsRetention {
+			delete(records, k)
+		}
+	}
+	content, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return ioutils.AtomicWriteFile(path, content, 0o600)
+}
+
+// lastPulled returns the time image with ID was last pulled by compose, if recorded
+func (s *composeService) lastPulled(id string) (time.Time, bool, error) {
+	pullRecordsMutex.Lock()
+	defer pullRecordsMutex.Unlock()
+
+	records, err := readPullRecords(s.pullRecordsPath())
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	pulled, ok := records[id]
+	return pulled, ok, nil
+}
@@ -0,0 +1,183 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestPullInterval(t *testing.T) {
+	tests := []struct {
+		policy   string
+		interval time.Duration
+		ok       bool
+		err      string
+	}{
+		{policy: types.PullPolicyAlways},
+		{policy: types.PullPolicyMissing},
+		{policy: "daily", interval: 24 * time.Hour, ok: true},
+		{policy: "weekly", interval: 7 * 24 * time.Hour, ok: true},
+		{policy: "every_12h", interval: 12 * time.Hour, ok: true},
+		{policy: "every_1h30m", interval: 90 * time.Minute, ok: true},
+		{policy: "every_3d", interval: 3 * 24 * time.Hour, ok: true},
+		{policy: "every_2w", interval: 14 * 24 * time.Hour, ok: true},
+		{policy: "every_", err: `invalid pull_policy "every_": interval must be a positive duration`},
+		{policy: "every_-1h", err: `invalid pull_policy "every_-1h": interval must be a positive duration`},
+		{policy: "every_xd", err: `invalid pull_policy "every_xd": interval must be a positive duration`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			interval, ok, err := PullInterval(tt.policy)
+			if tt.err != "" {
+				assert.Error(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, ok, tt.ok)
+			assert.Equal(t, interval, tt.interval)
+		})
+	}
+}
+
+func TestImagePulledWithinPolicy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(configfile.New(filepath.Join(t.TempDir(), "config.json"))).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	ctx := context.Background()
+	local := map[string]string{"recent": "sha256:1", "stale": "sha256:2", "loaded": "sha256:3", "retagged": "sha256:4"}
+
+	assert.NilError(t, tested.recordPull("sha256:1", time.Now().Add(-time.Hour)))
+	assert.NilError(t, tested.recordPull("sha256:2", time.Now().Add(-48*time.Hour)))
+	apiClient.EXPECT().ImageInspectWithRaw(ctx, "recent").Return(moby.ImageInspect{
+		ID: "sha256:1",
+	}, nil, nil).Times(2)
+	apiClient.EXPECT().ImageInspectWithRaw(ctx, "stale").Return(moby.ImageInspect{
+		ID: "sha256:2",
+		// tagging an image doesn't make it fresh
+		Metadata: image.Metadata{LastTagTime: time.Now()},
+	}, nil, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(ctx, "loaded").Return(moby.ImageInspect{
+		ID:      "sha256:3",
+		Created: time.Now().Add(-time.Minute).Format(time.RFC3339Nano),
+	}, nil, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(ctx, "retagged").Return(moby.ImageInspect{
+		ID:       "sha256:4",
+		Created:  time.Now().Add(-72 * time.Hour).Format(time.RFC3339Nano),
+		Metadata: image.Metadata{LastTagTime: time.Now()},
+	}, nil, nil)
+
+	for img, expected := range map[string]bool{"recent": true, "stale": false, "loaded": true, "retagged": false, "absent": false} {
+		fresh, err := tested.imagePulledWithinPolicy(ctx, types.ServiceConfig{Image: img, PullPolicy: "daily"}, local)
+		assert.NilError(t, err)
+		assert.Equal(t, fresh, expected, img)
+	}
+
+	fresh, err := tested.imagePulledWithinPolicy(ctx, types.ServiceConfig{Image: "recent", PullPolicy: "every_30m"}, local)
+	assert.NilError(t, err)
+	assert.Equal(t, fresh, false)
+
+	fresh, err = tested.imagePulledWithinPolicy(ctx, types.ServiceConfig{Image: "recent", PullPolicy: types.PullPolicyAlways}, local)
+	assert.NilError(t, err)
+	assert.Equal(t, fresh, false)
+}
+
+func TestRecordPull(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	_, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(configfile.New(filepath.Join(t.TempDir(), "config.json"))).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	_, ok, err := tested.lastPulled("sha256:1")
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	now := time.Now().Round(0)
+	assert.NilError(t, tested.recordPull("sha256:1", now.Add(-2*pullRecordsRetention)))
+	assert.NilError(t, tested.recordPull("sha256:2", now))
+	pulled, ok, err := tested.lastPulled("sha256:2")
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+	assert.Assert(t, pulled.Equal(now))
+
+	// records older than retention are dropped
+	_, ok, err = tested.lastPulled("sha256:1")
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+}
+
+func TestPullPolicyExtension(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(`
+services:
+  daily:
+    image: nginx
+    x-pull-policy: daily
+  custom:
+    image: nginx
+    x-pull-policy: every_12h
+  explicit:
+    image: nginx
+    pull_policy: always
+    x-pull-policy: weekly
+  invalid:
+    image: nginx
+    x-pull-policy: monthly
+`), 0o644))
+	options, err := cli.NewProjectOptions([]string{filepath.Join(dir, "compose.yaml")}, cli.WithName("test"))
+	assert.NilError(t, err)
+	project, err := options.LoadProject(context.Background())
+	assert.NilError(t, err)
+
+	policy, err := servicePullPolicy(project.Services["daily"])
+	assert.NilError(t, err)
+	assert.Equal(t, policy, "daily")
+
+	policy, err = servicePullPolicy(project.Services["custom"])
+	assert.NilError(t, err)
+	interval, ok, err := PullInterval(policy)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+	assert.Equal(t, interval, 12*time.Hour)
+
+	policy, err = servicePullPolicy(project.Services["explicit"])
+	assert.NilError(t, err)
+	assert.Equal(t, policy, types.PullPolicyAlways)
+
+	_, err = servicePullPolicy(project.Services["invalid"])
+	assert.ErrorContains(t, err, `x-pull-policy only supports daily, weekly and every_<duration>, got "monthly"`)
+}
@@ -117,31 +117,33 @@ func (s *composeService) pushServiceImage(ctx context.Context, tag string, info
 		return err
 	}
 
-	stream, err := s.apiClient().ImagePush(ctx, tag, image.PushOptions{
-		RegistryAuth: base64.URLEncoding.EncodeToString(buf),
-	})
-	if err != nil {
-		return err
-	}
-	dec := json.NewDecoder(stream)
-	for {
-		var jm jsonmessage.JSONMessage
-		if err := dec.Decode(&jm); err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
+	return s.withRegistryRetry(ctx, ref, fmt.Sprintf("Pushing %s", tag), w, func() error {
+		stream, err := s.apiClient().ImagePush(ctx, tag, image.PushOptions{
+			RegistryAuth: base64.URLEncoding.EncodeToString(buf),
+		})
+		if err != nil {
 			return err
 		}
-		if jm.Error != nil {
-			return errors.New(jm.Error.Message)
-		}
+		defer stream.Close() //nolint:errcheck
+
+		dec := json.NewDecoder(stream)
+		for {
+			var jm jsonmessage.JSONMessage
+			if err := dec.Decode(&jm); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			if jm.Error != nil {
+				return errors.New(jm.Error.Message)
+			}
 
-		if !quietPush {
-			toPushProgressEvent(tag, jm, w)
+			if !quietPush {
+				toPushProgressEvent(tag, jm, w)
+			}
 		}
-	}
-
-	return nil
+	})
 }
 
 func toPushProgressEvent(prefix string, jm jsonmessage.JSONMessage, w progress.Writer) {
@@ -0,0 +1,146 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/errdefs"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// defaultRegistryAttempts is the number of attempts for a registry operation when not configured
+const defaultRegistryAttempts = 3
+
+// maxRegistryRetryDelay caps the exponential backoff between attempts
+const maxRegistryRetryDelay = 30 * time.Second
+
+// registryRetryDelay is the delay before first retry, doubled for each subsequent one
+var registryRetryDelay = time.Second
+
+// transientRegistryErrors are messages reported by engine or registry for failures worth a retry
+var transientRegistryErrors = []string{
+	"toomanyrequests",
+	"too many requests",
+	"500 internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"connection reset by peer",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"tls handshake timeout",
+	"unexpected eof",
+}
+
+func (s *composeService) ConfigureRegistry(options api.RegistryOptions) {
+	if options.Attempts > 0 {
+		s.registryAttempts = options.Attempts
+	}
+	s.registryLimiter = newRegistryLimiter(options.MaxConcurrency)
+}
+
+// registryLimiter limits concurrent operations per registry host
+type registryLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+func newRegistryLimiter(limit int) *registryLimiter {
+	return &registryLimiter{
+		limit: limit,
+		slots: map[string]chan struct{}{},
+	}
+}
+
+// acquire waits for a slot to run an operation against registry host, and returns the func to release it
+func (l *registryLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil || l.limit <= 0 {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	slot, ok := l.slots[host]
+	if !ok {
+		slot = make(chan struct{}, l.limit)
+		l.slots[host] = slot
+	}
+	l.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// withRegistryRetry runs an operation against the registry hosting ref, retrying with exponential backoff
+// on transient failures. Each retry is reported as a progress event for id.
+func (s *composeService) withRegistryRetry(ctx context.Context, ref reference.Named, id string, w progress.Writer, fn func() error) error {
+	attempts := max(s.registryAttempts, 1)
+	delay := registryRetryDelay
+	for attempt := 1; ; attempt++ {
+		release, err := s.registryLimiter.acquire(ctx, reference.Domain(ref))
+		if err != nil {
+			return err
+		}
+		err = fn()
+		release()
+		if err == nil || attempt >= attempts || !isTransientRegistryError(err) {
+			return err
+		}
+
+		w.Event(progress.Event{
+			ID:         id,
+			Status:     progress.Working,
+			Text:       "Retrying",
+			StatusText: fmt.Sprintf("attempt %d/%d failed: %s", attempt, attempts, getUnwrappedErrorMessage(err)),
+		})
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay = min(delay*2, maxRegistryRetryDelay)
+	}
+}
+
+func isTransientRegistryError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errdefs.IsUnavailable(err) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientRegistryErrors {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
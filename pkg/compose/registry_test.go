@@ -0,0 +1,103 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/distribution/reference"
+	"gotest.tools/v3/assert"
+)
+
+func TestIsTransientRegistryError(t *testing.T) {
+	assert.Check(t, isTransientRegistryError(errors.New("toomanyrequests: You have reached your pull rate limit")))
+	assert.Check(t, isTransientRegistryError(errors.New("received unexpected HTTP status: 503 Service Unavailable")))
+	assert.Check(t, isTransientRegistryError(errors.New("read tcp 10.0.0.1:443: read: connection reset by peer")))
+	assert.Check(t, !isTransientRegistryError(errors.New("manifest for nginx:foo not found: manifest unknown")))
+	assert.Check(t, !isTransientRegistryError(errors.New("pull access denied for private/image")))
+	assert.Check(t, !isTransientRegistryError(context.Canceled))
+}
+
+func TestWithRegistryRetry(t *testing.T) {
+	delay := registryRetryDelay
+	registryRetryDelay = 0
+	defer func() { registryRetryDelay = delay }()
+
+	ref, err := reference.ParseNormalizedNamed("nginx")
+	assert.NilError(t, err)
+	tested := composeService{registryAttempts: 3}
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		w := &recordingWriter{}
+		calls := 0
+		err := tested.withRegistryRetry(context.Background(), ref, "web", w, func() error {
+			calls++
+			if calls < 3 {
+				return errors.New("429 Too Many Requests")
+			}
+			return nil
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, calls, 3)
+		assert.Equal(t, len(w.events), 2)
+		assert.Equal(t, w.events[0].Text, "Retrying")
+		assert.Equal(t, w.events[1].StatusText, "attempt 2/3 failed: 429 Too Many Requests")
+	})
+
+	t.Run("gives up after last attempt", func(t *testing.T) {
+		calls := 0
+		err := tested.withRegistryRetry(context.Background(), ref, "web", &recordingWriter{}, func() error {
+			calls++
+			return errors.New("502 Bad Gateway")
+		})
+		assert.Error(t, err, "502 Bad Gateway")
+		assert.Equal(t, calls, 3)
+	})
+
+	t.Run("fails fast on permanent errors", func(t *testing.T) {
+		calls := 0
+		err := tested.withRegistryRetry(context.Background(), ref, "web", &recordingWriter{}, func() error {
+			calls++
+			return errors.New("manifest unknown")
+		})
+		assert.Error(t, err, "manifest unknown")
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestRegistryLimiter(t *testing.T) {
+	limiter := newRegistryLimiter(1)
+	release, err := limiter.acquire(context.Background(), "docker.io")
+	assert.NilError(t, err)
+
+	// another registry isn't affected
+	other, err := limiter.acquire(context.Background(), "ghcr.io")
+	assert.NilError(t, err)
+	other()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = limiter.acquire(ctx, "docker.io")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	release()
+	release, err = limiter.acquire(context.Background(), "docker.io")
+	assert.NilError(t, err)
+	release()
+}
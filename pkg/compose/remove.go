@@ -104,16 +104,17 @@ func (s *composeService) remove(ctx context.Context, containers Containers, opti
 	for _, container := range containers {
 		container := container
 		eg.Go(func() error {
-			eventName := getContainerProgressName(container)
-			w.Event(progress.RemovingEvent(eventName))
+			notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRemoving))
 			err := s.apiClient().ContainerRemove(ctx, container.ID, containerType.RemoveOptions{
 				RemoveVolumes: options.Volumes,
 				Force:         options.Force,
 			})
-			if err == nil {
-				w.Event(progress.RemovedEvent(eventName))
+			if err != nil {
+				notifyContainersLifecycle(ctx, w, Containers{container}, api.LifecycleFailed, "Error while Removing", err)
+				return err
 			}
-			return err
+			notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRemoved))
+			return nil
 		})
 	}
 	return eg.Wait()
@@ -78,14 +78,15 @@ func (s *composeService) restart(ctx context.Context, projectName string, option
 		for _, container := range containers.filter(isService(service)) {
 			container := container
 			eg.Go(func() error {
-				eventName := getContainerProgressName(container)
-				w.Event(progress.RestartingEvent(eventName))
+				notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRestarting))
 				timeout := utils.DurationSecondToInt(options.Timeout)
 				err := s.apiClient().ContainerRestart(ctx, container.ID, containerType.StopOptions{Timeout: timeout})
-				if err == nil {
-					w.Event(progress.StartedEvent(eventName))
+				if err != nil {
+					notifyContainersLifecycle(ctx, w, Containers{container}, api.LifecycleFailed, "Error while Restarting", err)
+					return err
 				}
-				return err
+				notifyLifecycle(ctx, w, containerLifecycleEvent(container, api.LifecycleRestarted))
+				return nil
 			})
 		}
 		return eg.Wait()
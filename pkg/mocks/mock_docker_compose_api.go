@@ -54,6 +54,21 @@ func (mr *MockServiceMockRecorder) Attach(ctx, projectName, options any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attach", reflect.TypeOf((*MockService)(nil).Attach), ctx, projectName, options)
 }
 
+// Bake mocks base method.
+func (m *MockService) Bake(ctx context.Context, project *types.Project, options api.BakeOptions) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bake", ctx, project, options)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Bake indicates an expected call of Bake.
+func (mr *MockServiceMockRecorder) Bake(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bake", reflect.TypeOf((*MockService)(nil).Bake), ctx, project, options)
+}
+
 // Build mocks base method.
 func (m *MockService) Build(ctx context.Context, project *types.Project, options api.BuildOptions) error {
 	m.ctrl.T.Helper()
@@ -68,19 +83,30 @@ func (mr *MockServiceMockRecorder) Build(ctx, project, options any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockService)(nil).Build), ctx, project, options)
 }
 
-// Config mocks base method.
-func (m *MockService) Config(ctx context.Context, project *types.Project, options api.ConfigOptions) ([]byte, error) {
+// Bundle mocks base method.
+func (m *MockService) Bundle(ctx context.Context, project *types.Project, options api.BundleOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Config", ctx, project, options)
-	ret0, _ := ret[0].([]byte)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Bundle", ctx, project, options)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Config indicates an expected call of Config.
-func (mr *MockServiceMockRecorder) Config(ctx, project, options any) *gomock.Call {
+// Bundle indicates an expected call of Bundle.
+func (mr *MockServiceMockRecorder) Bundle(ctx, project, options any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Config", reflect.TypeOf((*MockService)(nil).Config), ctx, project, options)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bundle", reflect.TypeOf((*MockService)(nil).Bundle), ctx, project, options)
+}
+
+// ConfigureRegistry mocks base method.
+func (m *MockService) ConfigureRegistry(options api.RegistryOptions) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ConfigureRegistry", options)
+}
+
+// ConfigureRegistry indicates an expected call of ConfigureRegistry.
+func (mr *MockServiceMockRecorder) ConfigureRegistry(options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureRegistry", reflect.TypeOf((*MockService)(nil).ConfigureRegistry), options)
 }
 
 // Copy mocks base method.
@@ -169,6 +195,21 @@ func (mr *MockServiceMockRecorder) Exec(ctx, projectName, options any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockService)(nil).Exec), ctx, projectName, options)
 }
 
+// Export mocks base method.
+func (m *MockService) Export(ctx context.Context, project *types.Project, options api.ExportOptions) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, project, options)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockServiceMockRecorder) Export(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockService)(nil).Export), ctx, project, options)
+}
+
 // Images mocks base method.
 func (m *MockService) Images(ctx context.Context, projectName string, options api.ImagesOptions) ([]api.ImageSummary, error) {
 	m.ctrl.T.Helper()
@@ -198,6 +239,21 @@ func (mr *MockServiceMockRecorder) Kill(ctx, projectName, options any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Kill", reflect.TypeOf((*MockService)(nil).Kill), ctx, projectName, options)
 }
 
+// Lint mocks base method.
+func (m *MockService) Lint(ctx context.Context, project *types.Project, options api.LintOptions) ([]api.LintFinding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lint", ctx, project, options)
+	ret0, _ := ret[0].([]api.LintFinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lint indicates an expected call of Lint.
+func (mr *MockServiceMockRecorder) Lint(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lint", reflect.TypeOf((*MockService)(nil).Lint), ctx, project, options)
+}
+
 // List mocks base method.
 func (m *MockService) List(ctx context.Context, options api.ListOptions) ([]api.Stack, error) {
 	m.ctrl.T.Helper()
@@ -239,6 +295,21 @@ func (mr *MockServiceMockRecorder) MaxConcurrency(parallel any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxConcurrency", reflect.TypeOf((*MockService)(nil).MaxConcurrency), parallel)
 }
 
+// Outdated mocks base method.
+func (m *MockService) Outdated(ctx context.Context, project *types.Project, options api.OutdatedOptions) ([]api.ImageUpdate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outdated", ctx, project, options)
+	ret0, _ := ret[0].([]api.ImageUpdate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Outdated indicates an expected call of Outdated.
+func (mr *MockServiceMockRecorder) Outdated(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outdated", reflect.TypeOf((*MockService)(nil).Outdated), ctx, project, options)
+}
+
 // Pause mocks base method.
 func (m *MockService) Pause(ctx context.Context, projectName string, options api.PauseOptions) error {
 	m.ctrl.T.Helper()
@@ -440,6 +511,21 @@ func (mr *MockServiceMockRecorder) UnPause(ctx, projectName, options any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnPause", reflect.TypeOf((*MockService)(nil).UnPause), ctx, projectName, options)
 }
 
+// Unbundle mocks base method.
+func (m *MockService) Unbundle(ctx context.Context, options api.UnbundleOptions) (api.BundleManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unbundle", ctx, options)
+	ret0, _ := ret[0].(api.BundleManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unbundle indicates an expected call of Unbundle.
+func (mr *MockServiceMockRecorder) Unbundle(ctx, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unbundle", reflect.TypeOf((*MockService)(nil).Unbundle), ctx, options)
+}
+
 // Up mocks base method.
 func (m *MockService) Up(ctx context.Context, project *types.Project, options api.UpOptions) error {
 	m.ctrl.T.Helper()
@@ -36,6 +36,22 @@ func (s EventStatus) colorFn() colorFunc {
 	}
 }
 
+// String returns the stable lowercase name of the status, as used by the json progress writer
+func (s EventStatus) String() string {
+	switch s {
+	case Working:
+		return "working"
+	case Done:
+		return "done"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
 const (
 	// Working means that the current task is working
 	Working EventStatus = iota
@@ -57,10 +73,11 @@ type Event struct {
 	Current    int64
 	Percent    int
 
-	Total     int64
-	startTime time.Time
-	endTime   time.Time
-	spinner   *spinner
+	Total      int64
+	startTime  time.Time
+	endTime    time.Time
+	spinner    *spinner
+	throughput *throughput
 }
 
 // ErrorMessageEvent creates a new Error Event with message
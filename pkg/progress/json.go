@@ -0,0 +1,182 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package progress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type jsonWriter struct {
+	out        io.Writer
+	done       chan bool
+	dryRun     bool
+	mtx        sync.Mutex
+	startTimes map[string]time.Time
+}
+
+const (
+	// JSONMessageTypeEvent is the type of messages reporting a progress Event
+	JSONMessageTypeEvent = "event"
+	// JSONMessageTypeTail is the type of messages written once all events have been reported
+	JSONMessageTypeTail = "tail"
+	// JSONMessageTypeBuildKit is the type of messages holding a BuildKit solve status, as BuildKit json progress
+	// reports it, in BuildKit attribute
+	JSONMessageTypeBuildKit = "buildkit"
+)
+
+// JSONMessage is the NDJSON representation of a progress Event written by the json progress writer.
+// Field names are part of the public contract and must not change.
+type JSONMessage struct {
+	Type       string          `json:"type"`
+	Time       time.Time       `json:"time"`
+	DryRun     bool            `json:"dry_run,omitempty"`
+	Tail       bool            `json:"tail,omitempty"`
+	ID         string          `json:"id,omitempty"`
+	ParentID   string          `json:"parent_id,omitempty"`
+	Text       string          `json:"text,omitempty"`
+	Status     string          `json:"status,omitempty"`
+	StatusText string          `json:"status_text,omitempty"`
+	Current    int64           `json:"current,omitempty"`
+	Total      int64           `json:"total,omitempty"`
+	Percent    int             `json:"percent"`
+	StartTime  *time.Time      `json:"start_time,omitempty"`
+	EndTime    *time.Time      `json:"end_time,omitempty"`
+	BuildKit   json.RawMessage `json:"buildkit,omitempty"`
+}
+
+func (p *jsonWriter) Start(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return nil
+	}
+}
+
+func (p *jsonWriter) Event(e Event) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.event(e)
+}
+
+func (p *jsonWriter) event(e Event) {
+	now := time.Now()
+	start, ok := p.startTimes[e.ID]
+	if !ok {
+		start = now
+		p.startTimes[e.ID] = start
+	}
+	message := JSONMessage{
+		Type:       JSONMessageTypeEvent,
+		Time:       now,
+		DryRun:     p.dryRun,
+		ID:         e.ID,
+		ParentID:   e.ParentID,
+		Text:       e.Text,
+		Status:     e.Status.String(),
+		StatusText: e.StatusText,
+		Current:    e.Current,
+		Total:      e.Total,
+		Percent:    e.Percent,
+		StartTime:  &start,
+	}
+	if e.Status != Working {
+		message.EndTime = &now
+		delete(p.startTimes, e.ID)
+	}
+	p.write(message)
+}
+
+func (p *jsonWriter) Events(events []Event) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, e := range events {
+		p.event(e)
+	}
+}
+
+func (p *jsonWriter) TailMsgf(msg string, args ...interface{}) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.write(JSONMessage{
+		Type:       JSONMessageTypeTail,
+		Time:       time.Now(),
+		DryRun:     p.dryRun,
+		Tail:       true,
+		StatusText: fmt.Sprintf(msg, args...),
+	})
+}
+
+func (p *jsonWriter) write(message JSONMessage) {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(p.out, string(b))
+}
+
+func (p *jsonWriter) Stop() {
+	p.done <- true
+}
+
+// NewBuildKitJSONWriter returns a writer for BuildKit raw json progress, which wraps each solve status into a
+// JSONMessage, so it can be written to the same stream as compose json progress events
+func NewBuildKitJSONWriter(out io.Writer, dryRun bool) io.Writer {
+	return &buildKitJSONWriter{out: out, dryRun: dryRun}
+}
+
+type buildKitJSONWriter struct {
+	out    io.Writer
+	dryRun bool
+	mtx    sync.Mutex
+	buf    []byte
+}
+
+func (w *buildKitJSONWriter) Write(b []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		line := bytes.TrimSpace(w.buf[:i])
+		w.buf = w.buf[i+1:]
+		if len(line) == 0 {
+			continue
+		}
+		message, err := json.Marshal(JSONMessage{
+			Type:     JSONMessageTypeBuildKit,
+			Time:     time.Now(),
+			DryRun:   w.dryRun,
+			BuildKit: append(json.RawMessage{}, line...),
+		})
+		if err != nil {
+			return 0, err
+		}
+		if _, err := fmt.Fprintln(w.out, string(message)); err != nil {
+			return 0, err
+		}
+	}
+}
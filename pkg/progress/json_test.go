@@ -0,0 +1,138 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package progress
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestJSONWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &jsonWriter{
+		out:        out,
+		done:       make(chan bool),
+		startTimes: map[string]time.Time{},
+	}
+
+	w.Event(CreatingEvent("Container test"))
+	w.Events([]Event{
+		{
+			ID:       "layer",
+			ParentID: "Image test",
+			Status:   Working,
+			Current:  10,
+			Total:    20,
+			Percent:  50,
+		},
+		CreatedEvent("Container test"),
+	})
+	w.TailMsgf("done %s", "now")
+
+	var messages []JSONMessage
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var m JSONMessage
+		assert.NilError(t, json.Unmarshal(scanner.Bytes(), &m))
+		messages = append(messages, m)
+	}
+	assert.Equal(t, len(messages), 4)
+
+	assert.Equal(t, messages[0].Type, JSONMessageTypeEvent)
+	assert.Equal(t, messages[0].ID, "Container test")
+	assert.Equal(t, messages[0].Status, "working")
+	assert.Equal(t, messages[0].StatusText, "Creating")
+	assert.Assert(t, messages[0].StartTime != nil)
+	assert.Assert(t, messages[0].EndTime == nil)
+
+	assert.Equal(t, messages[1].ParentID, "Image test")
+	assert.Equal(t, messages[1].Current, int64(10))
+	assert.Equal(t, messages[1].Total, int64(20))
+	assert.Equal(t, messages[1].Percent, 50)
+
+	assert.Equal(t, messages[2].Status, "done")
+	assert.Equal(t, *messages[2].StartTime, *messages[0].StartTime)
+	assert.Assert(t, messages[2].EndTime != nil)
+
+	assert.Equal(t, messages[3].Type, JSONMessageTypeTail)
+	assert.Equal(t, messages[3].Tail, true)
+	assert.Equal(t, messages[3].StatusText, "done now")
+
+	// start times are only kept for events still in progress
+	_, ok := w.startTimes["Container test"]
+	assert.Assert(t, !ok)
+	_, ok = w.startTimes["layer"]
+	assert.Assert(t, ok)
+}
+
+func TestJSONWriterDryRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &jsonWriter{
+		out:        out,
+		done:       make(chan bool),
+		dryRun:     true,
+		startTimes: map[string]time.Time{},
+	}
+	w.Event(CreatingEvent("Container test"))
+
+	var m map[string]any
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &m))
+	assert.Equal(t, m["dry_run"], true)
+}
+
+func TestJSONWriterZeroPercent(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &jsonWriter{
+		out:        out,
+		done:       make(chan bool),
+		startTimes: map[string]time.Time{},
+	}
+	w.Event(Event{ID: "layer", Status: Working, Total: 20})
+
+	var m map[string]any
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &m))
+	assert.Equal(t, m["percent"], float64(0))
+}
+
+func TestBuildKitJSONWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewBuildKitJSONWriter(out, false)
+
+	// a status can be written in multiple chunks
+	_, err := w.Write([]byte(`{"vertexes":[{"digest":"sha256:abc",`))
+	assert.NilError(t, err)
+	assert.Equal(t, out.Len(), 0)
+	_, err = w.Write([]byte(`"name":"[web 1/2] FROM alpine"}]}` + "\n" + `{"logs":[]}` + "\n"))
+	assert.NilError(t, err)
+
+	var messages []JSONMessage
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var m JSONMessage
+		assert.NilError(t, json.Unmarshal(scanner.Bytes(), &m))
+		messages = append(messages, m)
+	}
+	assert.Equal(t, len(messages), 2)
+	assert.Equal(t, messages[0].Type, JSONMessageTypeBuildKit)
+	assert.Equal(t, string(messages[0].BuildKit), `{"vertexes":[{"digest":"sha256:abc","name":"[web 1/2] FROM alpine"}]}`)
+	assert.Equal(t, string(messages[1].BuildKit), `{"logs":[]}`)
+}
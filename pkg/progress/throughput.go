@@ -0,0 +1,67 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package progress
+
+import (
+	"time"
+)
+
+// throughputWindow is the period rolling throughput is computed over
+const throughputWindow = 5 * time.Second
+
+type sample struct {
+	time    time.Time
+	current int64
+}
+
+// throughput computes a rolling transfer rate based on progress samples
+type throughput struct {
+	samples []sample
+}
+
+func (t *throughput) add(now time.Time, current int64) {
+	if n := len(t.samples); n > 0 && t.samples[n-1].current == current {
+		return
+	}
+	t.samples = append(t.samples, sample{time: now, current: current})
+	// keep one sample older than window so rate covers the full period
+	for len(t.samples) > 2 && now.Sub(t.samples[1].time) > throughputWindow {
+		t.samples = t.samples[1:]
+	}
+}
+
+// rate returns the transfer rate in bytes per second
+func (t *throughput) rate(now time.Time) float64 {
+	if t == nil || len(t.samples) < 2 {
+		return 0
+	}
+	first, last := t.samples[0], t.samples[len(t.samples)-1]
+	elapsed := now.Sub(first.time).Seconds()
+	if elapsed <= 0 || last.current <= first.current {
+		return 0
+	}
+	return float64(last.current-first.current) / elapsed
+}
+
+// eta returns the estimated time to transfer remaining bytes, or 0 if unknown
+func (t *throughput) eta(now time.Time, current, total int64) time.Duration {
+	rate := t.rate(now)
+	if rate == 0 || total <= current {
+		return 0
+	}
+	return time.Duration(float64(total-current) / rate * float64(time.Second)).Round(time.Second)
+}
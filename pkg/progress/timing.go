@@ -0,0 +1,255 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// TimingsText prints a human readable summary of durations
+	TimingsText = "text"
+	// TimingsJSON dumps recorded spans as a JSON array
+	TimingsJSON = "json"
+	// TimingsTrace dumps recorded spans using Chrome trace-event format
+	TimingsTrace = "trace"
+)
+
+// Timings defines the format used to report durations once progress completes. Empty means disabled
+var Timings = ""
+
+// TimingsFile is the file timings report is written to. Report is written to progress output when not set
+var TimingsFile = ""
+
+// Span records the time spent by a resource in a phase, i.e. a sequence of events
+// sharing the same ID, starting with a Working status
+type Span struct {
+	ID       string        `json:"id"`
+	ParentID string        `json:"parent_id,omitempty"`
+	Phase    string        `json:"phase"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+}
+
+// timings records spans for the whole process, as a command may run multiple progress sessions
+var timings = newSpanRecorder()
+
+type spanRecorder struct {
+	mtx   sync.Mutex
+	open  map[string]*Span
+	spans []*Span
+}
+
+func newSpanRecorder() *spanRecorder {
+	return &spanRecorder{
+		open: map[string]*Span{},
+	}
+}
+
+// timingWriter decorates a Writer to record spans
+type timingWriter struct {
+	Writer
+	recorder *spanRecorder
+}
+
+func newTimingWriter(w Writer, recorder *spanRecorder) *timingWriter {
+	return &timingWriter{
+		Writer:   w,
+		recorder: recorder,
+	}
+}
+
+func (w *timingWriter) Event(e Event) {
+	w.recorder.record(e)
+	w.Writer.Event(e)
+}
+
+func (w *timingWriter) Events(events []Event) {
+	for _, e := range events {
+		w.recorder.record(e)
+	}
+	w.Writer.Events(events)
+}
+
+func (r *spanRecorder) record(e Event) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if e.startTime.IsZero() {
+		e.startTime = time.Now()
+	}
+	span, ok := r.open[e.ID]
+	if e.Status == Working {
+		if ok && span.Phase == e.StatusText {
+			return
+		}
+		if ok {
+			span.close(e.startTime)
+		}
+		span = &Span{
+			ID:       e.ID,
+			ParentID: e.ParentID,
+			Phase:    e.StatusText,
+			Start:    e.startTime,
+		}
+		r.open[e.ID] = span
+		r.spans = append(r.spans, span)
+		return
+	}
+	if ok {
+		if e.endTime.IsZero() {
+			e.endTime = e.startTime
+		}
+		span.close(e.endTime)
+		delete(r.open, e.ID)
+	}
+}
+
+func (s *Span) close(t time.Time) {
+	s.End = t
+	s.Duration = t.Sub(s.Start)
+}
+
+// Spans returns the recorded spans, closing the ones still in progress, and resets the recorder
+func (r *spanRecorder) Spans() []Span {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	now := time.Now()
+	for _, span := range r.open {
+		span.close(now)
+	}
+	spans := make([]Span, len(r.spans))
+	for i, s := range r.spans {
+		spans[i] = *s
+	}
+	r.open = map[string]*Span{}
+	r.spans = nil
+	return spans
+}
+
+// ReportTimings writes spans recorded by all progress sessions so far, using the Timings format.
+// Report is written to TimingsFile when set, otherwise to out
+func ReportTimings(out io.Writer) error {
+	if Timings == "" {
+		return nil
+	}
+	spans := timings.Spans()
+	if len(spans) == 0 {
+		return nil
+	}
+	if TimingsFile != "" {
+		f, err := os.Create(TimingsFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck
+		out = f
+	}
+	switch Timings {
+	case TimingsJSON:
+		return json.NewEncoder(out).Encode(spans)
+	case TimingsTrace:
+		return writeTraceEvents(out, spans)
+	default:
+		writeTimingsSummary(out, spans)
+		return nil
+	}
+}
+
+type phaseSummary struct {
+	name     string
+	duration time.Duration
+	spans    []Span
+}
+
+func writeTimingsSummary(out io.Writer, spans []Span) {
+	phases := map[string]*phaseSummary{}
+	var names []string
+	for _, s := range spans {
+		p, ok := phases[s.Phase]
+		if !ok {
+			p = &phaseSummary{name: s.Phase}
+			phases[s.Phase] = p
+			names = append(names, s.Phase)
+		}
+		p.duration += s.Duration
+		p.spans = append(p.spans, s)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return phases[names[i]].duration > phases[names[j]].duration
+	})
+
+	fmt.Fprintln(out, "Timings:")
+	for _, name := range names {
+		p := phases[name]
+		sort.SliceStable(p.spans, func(i, j int) bool {
+			return p.spans[i].Duration > p.spans[j].Duration
+		})
+		fmt.Fprintf(out, "  %-40s %8.1fs\n", p.name, p.duration.Seconds())
+		for _, s := range p.spans {
+			fmt.Fprintf(out, "    %-38s %8.1fs\n", s.ID, s.Duration.Seconds())
+		}
+	}
+}
+
+// traceEvent is a complete event as defined by the Chrome trace-event format
+// see https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Name     string            `json:"name"`
+	Category string            `json:"cat"`
+	Phase    string            `json:"ph"`
+	Time     int64             `json:"ts"`
+	Duration int64             `json:"dur"`
+	PID      int               `json:"pid"`
+	TID      int               `json:"tid"`
+	Args     map[string]string `json:"args,omitempty"`
+}
+
+func writeTraceEvents(out io.Writer, spans []Span) error {
+	threads := map[string]int{}
+	events := make([]traceEvent, 0, len(spans))
+	for _, s := range spans {
+		tid, ok := threads[s.ID]
+		if !ok {
+			tid = len(threads) + 1
+			threads[s.ID] = tid
+		}
+		event := traceEvent{
+			Name:     fmt.Sprintf("%s %s", s.Phase, s.ID),
+			Category: s.Phase,
+			Phase:    "X",
+			Time:     s.Start.UnixMicro(),
+			Duration: s.Duration.Microseconds(),
+			PID:      1,
+			TID:      tid,
+		}
+		if s.ParentID != "" {
+			event.Args = map[string]string{"parent": s.ParentID}
+		}
+		events = append(events, event)
+	}
+	return json.NewEncoder(out).Encode(map[string]any{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestTimingWriterSpans(t *testing.T) {
+	recorder := newSpanRecorder()
+	w := newTimingWriter(&noopWriter{}, recorder)
+
+	w.Event(CreatingEvent("Container a"))
+	w.Event(CreatedEvent("Container a"))
+	w.Events([]Event{
+		StartingEvent("Container a"),
+		Waiting("Container a"),
+		Healthy("Container a"),
+	})
+	w.Event(RemovedEvent("Container b"))
+
+	spans := recorder.Spans()
+	assert.Equal(t, len(spans), 3)
+	assert.Equal(t, spans[0].Phase, "Creating")
+	assert.Equal(t, spans[1].Phase, "Starting")
+	assert.Equal(t, spans[2].Phase, "Waiting")
+	for _, s := range spans {
+		assert.Equal(t, s.ID, "Container a")
+		assert.Assert(t, !s.End.Before(s.Start))
+	}
+}
+
+func TestReportTimingsAcrossSessions(t *testing.T) {
+	defer func(format string) { Timings = format }(Timings)
+	Timings = TimingsText
+
+	for _, id := range []string{"Container a", "Container b"} {
+		w := newTimingWriter(&noopWriter{}, timings)
+		w.Event(CreatingEvent(id))
+		w.Event(CreatedEvent(id))
+	}
+
+	out := &bytes.Buffer{}
+	assert.NilError(t, ReportTimings(out))
+	assert.Equal(t, strings.Count(out.String(), "Timings:"), 1)
+	assert.Assert(t, strings.Contains(out.String(), "Container a"))
+	assert.Assert(t, strings.Contains(out.String(), "Container b"))
+
+	out.Reset()
+	assert.NilError(t, ReportTimings(out))
+	assert.Equal(t, out.Len(), 0)
+}
+
+func TestTimingsSummary(t *testing.T) {
+	now := time.Now()
+	spans := []Span{
+		{ID: "Container a", Phase: "Creating", Start: now, End: now.Add(time.Second), Duration: time.Second},
+		{ID: "Image x", Phase: "Pulling", Start: now, End: now.Add(4 * time.Second), Duration: 4 * time.Second},
+		{ID: "Container b", Phase: "Creating", Start: now, End: now.Add(2 * time.Second), Duration: 2 * time.Second},
+	}
+	out := &bytes.Buffer{}
+	writeTimingsSummary(out, spans)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, len(lines), 6)
+	assert.Assert(t, strings.HasPrefix(strings.TrimSpace(lines[1]), "Pulling"))
+	assert.Assert(t, strings.HasPrefix(strings.TrimSpace(lines[3]), "Creating"))
+	assert.Assert(t, strings.HasPrefix(strings.TrimSpace(lines[4]), "Container b"))
+	assert.Assert(t, strings.HasPrefix(strings.TrimSpace(lines[5]), "Container a"))
+}
+
+func TestTraceEvents(t *testing.T) {
+	now := time.Now()
+	spans := []Span{
+		{ID: "Container a", Phase: "Creating", Start: now, Duration: time.Second},
+		{ID: "Container a", Phase: "Starting", Start: now.Add(time.Second), Duration: time.Second},
+	}
+	out := &bytes.Buffer{}
+	assert.NilError(t, writeTraceEvents(out, spans))
+
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &trace))
+	assert.Equal(t, len(trace.TraceEvents), 2)
+	assert.Equal(t, trace.TraceEvents[0].Phase, "X")
+	assert.Equal(t, trace.TraceEvents[0].Duration, int64(1000000))
+	assert.Equal(t, trace.TraceEvents[0].TID, trace.TraceEvents[1].TID)
+}
@@ -44,6 +44,8 @@ type ttyWriter struct {
 	dryRun          bool
 	skipChildEvents bool
 	progressTitle   string
+	startTime       time.Time
+	throughput      *throughput
 }
 
 func (w *ttyWriter) Start(ctx context.Context) error {
@@ -116,6 +118,56 @@ func (w *ttyWriter) event(e Event) {
 		}
 		w.events[e.ID] = e
 	}
+	if e.ParentID != "" {
+		w.aggregate(e.ParentID)
+	}
+}
+
+// aggregate sums up children progress into parent event, and keeps track of parent throughput
+func (w *ttyWriter) aggregate(parentID string) {
+	parent, ok := w.events[parentID]
+	if !ok {
+		return
+	}
+	var current, total int64
+	for _, id := range w.eventIDs {
+		child := w.events[id]
+		if child.ParentID == parentID {
+			current += child.Current
+			total += child.Total
+		}
+	}
+	if current > parent.Current {
+		parent.Current = current
+	}
+	if total > parent.Total {
+		parent.Total = total
+	}
+	if parent.throughput == nil {
+		parent.throughput = &throughput{}
+	}
+	now := time.Now()
+	parent.throughput.add(now, parent.Current)
+	w.events[parentID] = parent
+
+	if w.throughput == nil {
+		w.throughput = &throughput{}
+	}
+	overall, _ := w.transferred()
+	w.throughput.add(now, overall)
+}
+
+// transferred returns the overall bytes downloaded and expected for all child events.
+// Only pulled layers are reported as children, so pushes are not accounted as downloads
+func (w *ttyWriter) transferred() (int64, int64) {
+	var current, total int64
+	for _, e := range w.events {
+		if e.ParentID != "" {
+			current += e.Current
+			total += e.Total
+		}
+	}
+	return current, total
 }
 
 func (w *ttyWriter) Events(events []Event) {
@@ -166,7 +218,12 @@ func (w *ttyWriter) print() { //nolint:gocyclo
 	defer fmt.Fprint(w.out, aec.Show)
 
 	firstLine := fmt.Sprintf("[+] %s %d/%d", w.progressTitle, numDone(w.events), w.numLines)
-	if w.numLines != 0 && numDone(w.events) == w.numLines {
+	allDone := w.numLines != 0 && numDone(w.events) == w.numLines
+	current, total := w.transferred()
+	if allDone && current > 0 {
+		firstLine = fmt.Sprintf("%s (%s downloaded in %.1fs)", firstLine, units.HumanSize(float64(current)), time.Since(w.startTime).Seconds())
+	}
+	if allDone {
 		firstLine = DoneColor(firstLine)
 	}
 	fmt.Fprintln(w.out, firstLine)
@@ -207,6 +264,10 @@ func (w *ttyWriter) print() { //nolint:gocyclo
 			}
 		}
 	}
+	if !allDone && total > 0 && numLines < goterm.Height()-2 {
+		fmt.Fprint(w.out, w.footer(current, total, terminalWidth))
+		numLines++
+	}
 	for i := numLines; i < w.numLines; i++ {
 		if numLines < goterm.Height()-2 {
 			fmt.Fprintln(w.out, strings.Repeat(" ", terminalWidth))
@@ -233,8 +294,6 @@ func (w *ttyWriter) lineText(event Event, pad string, terminalWidth, statusPaddi
 
 	var (
 		hideDetails bool
-		total       int64
-		current     int64
 		completion  []string
 	)
 
@@ -248,15 +307,13 @@ func (w *ttyWriter) lineText(event Event, pad string, terminalWidth, statusPaddi
 					// so don't show the total progress yet
 					hideDetails = true
 				}
-				total += child.Total
-				current += child.Current
 				completion = append(completion, percentChars[(len(percentChars)-1)*child.Percent/100])
 			}
 		}
 	}
 
 	// don't try to show detailed progress if we don't have any idea
-	if total == 0 {
+	if event.Total == 0 {
 		hideDetails = true
 	}
 
@@ -264,7 +321,14 @@ func (w *ttyWriter) lineText(event Event, pad string, terminalWidth, statusPaddi
 	if len(completion) > 0 {
 		var details string
 		if !hideDetails {
-			details = fmt.Sprintf(" %7s / %-7s", units.HumanSize(float64(current)), units.HumanSize(float64(total)))
+			details = fmt.Sprintf(" %7s / %-7s", units.HumanSize(float64(event.Current)), units.HumanSize(float64(event.Total)))
+			now := time.Now()
+			if rate := event.throughput.rate(now); rate > 0 {
+				details += fmt.Sprintf(" %s/s", units.HumanSize(rate))
+			}
+			if eta := event.throughput.eta(now, event.Current, event.Total); eta > 0 {
+				details += fmt.Sprintf(" ETA %s", eta)
+			}
 		}
 		txt = fmt.Sprintf("%s [%s]%s %s",
 			event.ID,
@@ -302,6 +366,20 @@ func (w *ttyWriter) lineText(event Event, pad string, terminalWidth, statusPaddi
 	return o
 }
 
+// footer renders project-wide transfer progress
+func (w *ttyWriter) footer(current, total int64, terminalWidth int) string {
+	now := time.Now()
+	txt := fmt.Sprintf(" %s / %s", units.HumanSize(float64(current)), units.HumanSize(float64(total)))
+	if rate := w.throughput.rate(now); rate > 0 {
+		txt += fmt.Sprintf(" %s/s", units.HumanSize(rate))
+	}
+	if eta := w.throughput.eta(now, current, total); eta > 0 {
+		txt += fmt.Sprintf(" ETA %s", eta)
+	}
+	elapsed := fmt.Sprintf("%.1fs ", now.Sub(w.startTime).Seconds())
+	return align(txt, TimerColor(elapsed), terminalWidth)
+}
+
 func numDone(events map[string]Event) int {
 	i := 0
 	for _, e := range events {
@@ -134,6 +134,48 @@ func TestWarningEvent(t *testing.T) {
 	assert.Assert(t, event.endTime.After(time.Now().Add(-10*time.Second)))
 }
 
+func TestAggregateChildProgress(t *testing.T) {
+	w := tty()
+	w.Event(Event{ID: "service", Status: Working, StatusText: "Pulling"})
+	w.Events([]Event{
+		{ID: "layer1", ParentID: "service", Status: Working, Current: 10, Total: 100},
+		{ID: "layer2", ParentID: "service", Status: Working, Current: 30, Total: 200},
+	})
+	parent := w.events["service"]
+	assert.Equal(t, parent.Current, int64(40))
+	assert.Equal(t, parent.Total, int64(300))
+
+	current, total := w.transferred()
+	assert.Equal(t, current, int64(40))
+	assert.Equal(t, total, int64(300))
+}
+
+func TestPushNotTransferred(t *testing.T) {
+	w := tty()
+	w.Event(Event{ID: "Pushing", Status: Working})
+	w.Event(Event{ID: "image", Status: Working, Current: 50, Total: 100})
+
+	current, total := w.transferred()
+	assert.Equal(t, current, int64(0))
+	assert.Equal(t, total, int64(0))
+}
+
+func TestThroughput(t *testing.T) {
+	now := time.Now()
+	tp := &throughput{}
+	assert.Equal(t, tp.rate(now), float64(0))
+
+	tp.add(now, 0)
+	tp.add(now.Add(time.Second), 100)
+	tp.add(now.Add(2*time.Second), 200)
+	assert.Equal(t, tp.rate(now.Add(2*time.Second)), float64(100))
+	assert.Equal(t, tp.eta(now.Add(2*time.Second), 200, 700), 5*time.Second)
+
+	// samples older than window are discarded
+	tp.add(now.Add(20*time.Second), 300)
+	assert.Equal(t, len(tp.samples), 2)
+}
+
 func tty() *ttyWriter {
 	tty := &ttyWriter{
 		eventIDs: []string{},
@@ -20,6 +20,7 @@ import (
 	"context"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/containerd/console"
 	"github.com/moby/term"
@@ -54,6 +55,24 @@ func ContextWriter(ctx context.Context) Writer {
 	return s
 }
 
+type outputKey struct{}
+
+type output struct {
+	out  io.Writer
+	mode string
+}
+
+// WithOutput overrides the output and mode used to render progress by the Run functions invoked with the returned context
+func WithOutput(ctx context.Context, out io.Writer, mode string) context.Context {
+	return context.WithValue(ctx, outputKey{}, output{out: out, mode: mode})
+}
+
+// ContextOutput returns the output and mode set by WithOutput, if any
+func ContextOutput(ctx context.Context) (io.Writer, string, bool) {
+	o, ok := ctx.Value(outputKey{}).(output)
+	return o.out, o.mode, ok
+}
+
 type progressFunc func(context.Context) error
 
 type progressFuncWithStatus func(context.Context) (string, error)
@@ -109,6 +128,8 @@ const (
 	ModePlain = "plain"
 	// ModeQuiet don't display events
 	ModeQuiet = "quiet"
+	// ModeJSON outputs a machine-readable JSON representation of each event
+	ModeJSON = "json"
 )
 
 // Mode define how progress should be rendered, either as ModePlain or ModeTTY
@@ -116,19 +137,39 @@ var Mode = ModeAuto
 
 // NewWriter returns a new multi-progress writer
 func NewWriter(ctx context.Context, out io.Writer, progressTitle string) (Writer, error) {
+	mode := Mode
+	if o, m, ok := ContextOutput(ctx); ok {
+		out, mode = o, m
+	}
+	w, err := newWriter(ctx, out, mode, progressTitle)
+	if err != nil || Timings == "" {
+		return w, err
+	}
+	return newTimingWriter(w, timings), nil
+}
+
+func newWriter(ctx context.Context, out io.Writer, mode string, progressTitle string) (Writer, error) {
 	_, isTerminal := term.GetFdInfo(out)
 	dryRun, ok := ctx.Value(api.DryRunKey{}).(bool)
 	if !ok {
 		dryRun = false
 	}
-	if Mode == ModeQuiet {
+	if mode == ModeQuiet {
 		return quiet{}, nil
 	}
+	if mode == ModeJSON {
+		return &jsonWriter{
+			out:        out,
+			done:       make(chan bool),
+			dryRun:     dryRun,
+			startTimes: map[string]time.Time{},
+		}, nil
+	}
 	f, isConsole := out.(console.File) // see https://github.com/docker/compose/issues/10560
-	if Mode == ModeAuto && isTerminal && isConsole {
+	if mode == ModeAuto && isTerminal && isConsole {
 		return newTTYWriter(f, dryRun, progressTitle)
 	}
-	if Mode == ModeTTY {
+	if mode == ModeTTY {
 		if !isConsole {
 			logrus.Warn("Terminal is not a POSIX console")
 		} else {
@@ -157,5 +198,7 @@ func newTTYWriter(out console.File, dryRun bool, progressTitle string) (Writer,
 		mtx:           &sync.Mutex{},
 		dryRun:        dryRun,
 		progressTitle: progressTitle,
+		startTime:     time.Now(),
+		throughput:    &throughput{},
 	}, nil
 }
@@ -17,20 +17,356 @@
 package remote
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/gofrs/flock"
+)
+
+const (
+	// CacheEntryGit is the kind of cache entries holding a git checkout
+	CacheEntryGit = "git"
+	// CacheEntryOCI is the kind of cache entries holding files pulled from an OCI artifact
+	CacheEntryOCI = "oci"
+	// CacheEntryHTTP is the kind of cache entries holding a file downloaded from an HTTPS URL
+	CacheEntryHTTP = "http"
 )
 
+// CacheEntry describes a remote resource stored in local cache
+type CacheEntry struct {
+	Key      string    `json:"key"`
+	Kind     string    `json:"kind"`
+	Source   string    `json:"source"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed"`
+	Size     int64     `json:"size"`
+}
+
+// PruneCacheOptions group options of the PruneCache function
+type PruneCacheOptions struct {
+	// All removes all entries
+	All bool
+	// OlderThan removes entries not used for this duration
+	OlderThan time.Duration
+	// MaxSize removes least recently used entries until cache size is under this limit
+	MaxSize int64
+}
+
 func cacheDir() (string, error) {
-	cache, ok := os.LookupEnv("XDG_CACHE_HOME")
+	path, ok := os.LookupEnv("XDG_CACHE_HOME")
 	if ok {
-		return filepath.Join(cache, "docker-compose"), nil
+		path = filepath.Join(path, "docker-compose")
+	} else {
+		var err error
+		path, err = osDependentCacheDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	err := os.MkdirAll(path, 0o700)
+	return path, err
+}
+
+// isCacheKey tells if name within cache directory is a cache entry, not a metadata, lock or temporary file
+func isCacheKey(name string) bool {
+	return name != "" && !strings.Contains(name, ".")
+}
+
+// lockCacheEntry prevents concurrent compose invocations to populate or remove the same cache entry
+func lockCacheEntry(ctx context.Context, cache string, key string) (func(), error) {
+	lock := flock.New(filepath.Join(cache, key+".lock"))
+	locked, err := lock.TryLockContext(ctx, 100*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("locking remote resource cache entry %s: %w", key, err)
+	}
+	if !locked {
+		return nil, fmt.Errorf("failed to lock remote resource cache entry %s", key)
 	}
+	return func() {
+		_ = lock.Unlock()
+	}, nil
+}
 
-	path, err := osDependentCacheDir()
+// populateCacheEntry creates cache entry key using fill, unless it already exists. fill writes into a temporary
+// directory which is renamed once complete, so an interrupted download never leaves a partial entry behind.
+// Existing entry is replaced when refresh is set.
+func populateCacheEntry(ctx context.Context, cache string, key string, refresh bool, fill func(dir string) error) (string, error) {
+	unlock, err := lockCacheEntry(ctx, cache, key)
 	if err != nil {
 		return "", err
 	}
-	err = os.MkdirAll(path, 0o700)
-	return path, err
+	defer unlock()
+
+	local := filepath.Join(cache, key)
+	if refresh {
+		if err := os.RemoveAll(local); err != nil {
+			return "", err
+		}
+	}
+	if _, err := os.Stat(local); err == nil {
+		return local, nil
+	}
+
+	tmp, err := os.MkdirTemp(cache, key+".tmp-")
+	if err != nil {
+		return "", err
+	}
+	if err := fill(tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, local); err != nil {
+		_ = os.RemoveAll(tmp)
+		if _, serr := os.Stat(local); serr == nil {
+			// populated by a process not honoring lock, i.e. an older compose release
+			return local, nil
+		}
+		return "", err
+	}
+	return local, nil
+}
+
+// recordCacheUse updates metadata of a cache entry with source reference and last-used time
+func recordCacheUse(cache string, key string, kind string, source string) error {
+	now := time.Now().UTC()
+	entry, err := readCacheMetadata(cache, key)
+	if err != nil {
+		return err
+	}
+	if entry.Created.IsZero() {
+		entry.Created = now
+	}
+	entry.Key = key
+	entry.Kind = kind
+	entry.Source = source
+	entry.LastUsed = now
+	entry.Size = 0 // computed when listing
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(cache, key+".json"), content)
+}
+
+func readCacheMetadata(cache string, key string) (CacheEntry, error) {
+	var entry CacheEntry
+	content, err := os.ReadFile(filepath.Join(cache, key+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return entry, nil
+	}
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(content, &entry)
+	return entry, err
+}
+
+// ListCache returns remote resources stored in local cache, most recently used first
+func ListCache() ([]CacheEntry, error) {
+	cache, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := os.ReadDir(cache)
+	if err != nil {
+		return nil, err
+	}
+	entries := []CacheEntry{}
+	for _, d := range dirs {
+		if !d.IsDir() || !isCacheKey(d.Name()) {
+			continue
+		}
+		entry, err := readCacheMetadata(cache, d.Name())
+		if err != nil {
+			return nil, err
+		}
+		entry.Key = d.Name()
+		if entry.LastUsed.IsZero() {
+			// populated by an older compose release, which didn't record metadata
+			info, err := d.Info()
+			if err != nil {
+				return nil, err
+			}
+			entry.Created = info.ModTime()
+			entry.LastUsed = info.ModTime()
+		}
+		entry.Size, err = dirSize(filepath.Join(cache, d.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// PruneCache removes cache entries selected by options, and returns those removed.
+// Entries locked by a concurrent compose invocation are skipped.
+func PruneCache(ctx context.Context, options PruneCacheOptions) ([]CacheEntry, error) {
+	cache, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ListCache()
+	if err != nil {
+		return nil, err
+	}
+
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	// entries are sorted most recently used first, so iterate backward to evict least recently used
+	removed := []CacheEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		expired := options.OlderThan > 0 && time.Since(e.LastUsed) > options.OlderThan
+		oversize := options.MaxSize > 0 && size > options.MaxSize
+		if !options.All && !expired && !oversize {
+			continue
+		}
+		ok, err := removeCacheEntry(ctx, cache, e.Key)
+		if err != nil {
+			return removed, err
+		}
+		if ok {
+			size -= e.Size
+			removed = append(removed, e)
+		}
+	}
+	return removed, pruneStaleFiles(ctx, cache)
+}
+
+// pruneStaleFiles removes temporary directories left by an interrupted download, as well as metadata files for
+// entries which don't exist anymore. Files for entries locked by a concurrent compose invocation are kept.
+// Lock files are never removed, as a concurrent invocation could be holding a lock on the unlinked file while
+// another one creates and locks a new file for the same entry
+func pruneStaleFiles(ctx context.Context, cache string) error {
+	files, err := os.ReadDir(cache)
+	if err != nil {
+		return err
+	}
+	stale := map[string][]string{}
+	for _, f := range files {
+		name := f.Name()
+		var key string
+		switch {
+		case f.IsDir() && strings.Contains(name, ".tmp-"):
+			key, _, _ = strings.Cut(name, ".tmp-")
+		case strings.HasSuffix(name, ".json"):
+			key = strings.TrimSuffix(name, ".json")
+		default:
+			continue
+		}
+		if !isCacheKey(key) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(cache, key)); err == nil && !f.IsDir() {
+			// metadata of an existing entry
+			continue
+		}
+		stale[key] = append(stale[key], name)
+	}
+	for key, names := range stale {
+		err := withCacheEntryLock(ctx, cache, key, func() error {
+			for _, name := range names {
+				if err := os.RemoveAll(filepath.Join(cache, name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withCacheEntryLock runs fn while holding cache entry lock, or skips it if entry is in use
+func withCacheEntryLock(ctx context.Context, cache string, key string, fn func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	unlock, err := lockCacheEntry(ctx, cache, key)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil // in use
+		}
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+func removeCacheEntry(ctx context.Context, cache string, key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	unlock, err := lockCacheEntry(ctx, cache, key)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, nil // in use
+		}
+		return false, err
+	}
+	defer unlock()
+	if err := os.RemoveAll(filepath.Join(cache, key)); err != nil {
+		return false, err
+	}
+	err = os.Remove(filepath.Join(cache, key+".json"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	return true, nil
+}
+
+// RefreshCache resolves a git, OCI or HTTPS remote reference again and replaces the cached resource, so a floating
+// git branch or OCI tag is updated
+func RefreshCache(ctx context.Context, dockerCli command.Cli, source string) error {
+	switch {
+	case gitRemoteLoader{}.Accept(source):
+		loader := gitRemoteLoader{dockerCli: dockerCli, known: map[string]string{}, refresh: true}
+		_, err := loader.Load(ctx, source)
+		return err
+	case ociRemoteLoader{}.Accept(source):
+		loader := ociRemoteLoader{dockerCli: dockerCli, known: map[string]string{}, refresh: true}
+		_, err := loader.Load(ctx, source)
+		return err
+	case httpRemoteLoader{}.Accept(source):
+		loader := httpRemoteLoader{client: http.DefaultClient, known: map[string]string{}, refresh: true}
+		_, err := loader.Load(ctx, source)
+		return err
+	default:
+		return fmt.Errorf("%s is not a git, OCI or HTTPS remote reference", source)
+	}
+}
+
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
 }
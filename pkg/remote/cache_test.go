@@ -0,0 +1,213 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func testCacheDir(t *testing.T) string {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := cacheDir()
+	assert.NilError(t, err)
+	return cache
+}
+
+// addCacheEntry creates a cache entry with content of given size, last used at given time
+func addCacheEntry(t *testing.T, cache string, key string, size int, lastUsed time.Time) {
+	assert.NilError(t, os.Mkdir(filepath.Join(cache, key), 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(cache, key, "compose.yaml"), make([]byte, size), 0o600))
+	content, err := json.Marshal(CacheEntry{Key: key, Created: lastUsed, LastUsed: lastUsed})
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(filepath.Join(cache, key+".json"), content, 0o600))
+}
+
+func cacheFiles(t *testing.T, cache string) []string {
+	files, err := os.ReadDir(cache)
+	assert.NilError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	return names
+}
+
+func TestIsCacheKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "0123456789abcdef", expected: true},
+		{name: "0123456789abcdef.json"},
+		{name: "0123456789abcdef.lock"},
+		{name: "0123456789abcdef.tmp-1234"},
+		{name: ".refs"},
+		{name: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, isCacheKey(tt.name), tt.expected)
+		})
+	}
+}
+
+func TestPopulateCacheEntry(t *testing.T) {
+	cache := testCacheDir(t)
+	ctx := context.Background()
+
+	fills := 0
+	fill := func(dir string) error {
+		fills++
+		return os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {}"), 0o600)
+	}
+
+	local, err := populateCacheEntry(ctx, cache, "entry", false, fill)
+	assert.NilError(t, err)
+	assert.Equal(t, local, filepath.Join(cache, "entry"))
+	assert.Equal(t, fills, 1)
+	content, err := os.ReadFile(filepath.Join(local, "compose.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "services: {}")
+
+	_, err = populateCacheEntry(ctx, cache, "entry", false, fill)
+	assert.NilError(t, err)
+	assert.Equal(t, fills, 1, "existing entry should be reused")
+
+	_, err = populateCacheEntry(ctx, cache, "entry", true, fill)
+	assert.NilError(t, err)
+	assert.Equal(t, fills, 2, "entry should be filled again on refresh")
+
+	assert.DeepEqual(t, cacheFiles(t, cache), []string{"entry", "entry.lock"})
+}
+
+func TestPopulateCacheEntryFailure(t *testing.T) {
+	cache := testCacheDir(t)
+
+	_, err := populateCacheEntry(context.Background(), cache, "entry", false, func(dir string) error {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("partial"), 0o600))
+		return errors.New("download interrupted")
+	})
+	assert.Error(t, err, "download interrupted")
+
+	// neither a partial entry nor the temporary directory is left behind
+	assert.DeepEqual(t, cacheFiles(t, cache), []string{"entry.lock"})
+}
+
+func TestRecordCacheUse(t *testing.T) {
+	cache := testCacheDir(t)
+
+	assert.NilError(t, recordCacheUse(cache, "entry", "git", "https://github.com/docker/compose.git"))
+	first, err := readCacheMetadata(cache, "entry")
+	assert.NilError(t, err)
+	assert.Equal(t, first.Key, "entry")
+	assert.Equal(t, first.Kind, "git")
+	assert.Equal(t, first.Source, "https://github.com/docker/compose.git")
+	assert.Assert(t, !first.Created.IsZero())
+	assert.Equal(t, first.Created, first.LastUsed)
+
+	assert.NilError(t, recordCacheUse(cache, "entry", "git", "https://github.com/docker/compose.git#v2"))
+	second, err := readCacheMetadata(cache, "entry")
+	assert.NilError(t, err)
+	assert.Equal(t, second.Source, "https://github.com/docker/compose.git#v2")
+	assert.Equal(t, second.Created, first.Created, "creation time should be preserved")
+	assert.Assert(t, !second.LastUsed.Before(first.LastUsed))
+}
+
+func TestPruneCache(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		options  PruneCacheOptions
+		expected []string
+	}{
+		{
+			name:    "nothing selected",
+			options: PruneCacheOptions{},
+		},
+		{
+			name:     "all",
+			options:  PruneCacheOptions{All: true},
+			expected: []string{"older", "old", "recent"},
+		},
+		{
+			name:     "older than",
+			options:  PruneCacheOptions{OlderThan: 36 * time.Hour},
+			expected: []string{"older"},
+		},
+		{
+			name:     "max size evicts least recently used",
+			options:  PruneCacheOptions{MaxSize: 150},
+			expected: []string{"older", "old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := testCacheDir(t)
+			addCacheEntry(t, cache, "recent", 100, now)
+			addCacheEntry(t, cache, "old", 100, now.Add(-24*time.Hour))
+			addCacheEntry(t, cache, "older", 100, now.Add(-48*time.Hour))
+
+			removed, err := PruneCache(context.Background(), tt.options)
+			assert.NilError(t, err)
+			var keys []string
+			for _, e := range removed {
+				keys = append(keys, e.Key)
+			}
+			assert.DeepEqual(t, keys, tt.expected)
+			for _, key := range tt.expected {
+				_, err := os.Stat(filepath.Join(cache, key))
+				assert.Assert(t, os.IsNotExist(err))
+				_, err = os.Stat(filepath.Join(cache, key+".json"))
+				assert.Assert(t, os.IsNotExist(err))
+			}
+		})
+	}
+}
+
+func TestPruneCacheStaleFiles(t *testing.T) {
+	cache := testCacheDir(t)
+	ctx := context.Background()
+	addCacheEntry(t, cache, "entry", 10, time.Now())
+
+	// lock file of an existing entry
+	assert.NilError(t, os.WriteFile(filepath.Join(cache, "entry.lock"), nil, 0o600))
+	// interrupted download
+	assert.NilError(t, os.Mkdir(filepath.Join(cache, "entry.tmp-1234"), 0o700))
+	assert.NilError(t, os.Mkdir(filepath.Join(cache, "gone.tmp-5678"), 0o700))
+	// leftovers of a removed entry
+	assert.NilError(t, os.WriteFile(filepath.Join(cache, "gone.lock"), nil, 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(cache, "gone.json"), []byte("{}"), 0o600))
+
+	// download in progress by a concurrent invocation
+	unlock, err := lockCacheEntry(ctx, cache, "busy")
+	assert.NilError(t, err)
+	defer unlock()
+	assert.NilError(t, os.Mkdir(filepath.Join(cache, "busy.tmp-9999"), 0o700))
+
+	removed, err := PruneCache(ctx, PruneCacheOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(removed), 0)
+	assert.DeepEqual(t, cacheFiles(t, cache), []string{"busy.lock", "busy.tmp-9999", "entry", "entry.json", "entry.lock", "gone.lock"})
+}
@@ -0,0 +1,134 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/store/storeutil"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/cli/cli/command"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/docker/compose/v2/internal/ocipush"
+)
+
+// FetchMetadataFile is the file recording the source of a fetched project
+const FetchMetadataFile = ".compose-artifact.json"
+
+// FetchMetadata records the published artifact a project has been fetched from
+type FetchMetadata struct {
+	Reference string    `json:"reference"`
+	Digest    string    `json:"digest"`
+	Fetched   time.Time `json:"fetched"`
+	Files     []string  `json:"files"`
+}
+
+// FetchOCIArtifact downloads a compose project published to a registry, and writes its layers as files into dir.
+// Existing files are only overwritten when force is set.
+func FetchOCIArtifact(ctx context.Context, dockerCli command.Cli, ref string, dir string, force bool) (FetchMetadata, error) {
+	var metadata FetchMetadata
+	named, err := reference.ParseDockerRef(strings.TrimPrefix(ref, prefix))
+	if err != nil {
+		return metadata, err
+	}
+
+	opt, err := storeutil.GetImageConfig(dockerCli, nil)
+	if err != nil {
+		return metadata, err
+	}
+	resolver := imagetools.New(opt)
+
+	manifest, descriptor, err := getComposeManifest(ctx, resolver, named)
+	if err != nil {
+		return metadata, err
+	}
+	verifier, err := newSignatureVerifier(dockerCli)
+	if err != nil {
+		return metadata, err
+	}
+	if err := verifier.verify(ctx, opt, named, descriptor.Digest); err != nil {
+		return metadata, err
+	}
+	if err := validateComposeManifest(named, manifest); err != nil {
+		return metadata, err
+	}
+
+	files := map[string][]byte{}
+	metadata = FetchMetadata{
+		Reference: named.String(),
+		Digest:    descriptor.Digest.String(),
+		Fetched:   time.Now().UTC(),
+	}
+	for _, layer := range manifest.Layers {
+		name := layerFileName(layer)
+		if name == "" {
+			return metadata, fmt.Errorf("layer %s has no file name annotation", layer.Digest)
+		}
+		if _, ok := files[name]; ok {
+			return metadata, fmt.Errorf("artifact has multiple layers for file %s", name)
+		}
+		data, err := getBlob(ctx, resolver, reference.TrimNamed(named), layer)
+		if err != nil {
+			return metadata, err
+		}
+		files[name] = data
+		metadata.Files = append(metadata.Files, name)
+	}
+
+	// check all files before writing any, so a conflict doesn't leave a partially fetched project
+	targets := map[string]string{}
+	for _, name := range append(metadata.Files, FetchMetadataFile) {
+		target, err := projectFilePath(dir, name)
+		if err != nil {
+			return metadata, err
+		}
+		if _, err := os.Stat(target); err == nil && !force {
+			return metadata, fmt.Errorf("%s already exists, use --force to overwrite", target)
+		}
+		targets[name] = target
+	}
+
+	for _, name := range metadata.Files {
+		if err := os.MkdirAll(filepath.Dir(targets[name]), 0o755); err != nil {
+			return metadata, err
+		}
+		if err := os.WriteFile(targets[name], files[name], 0o644); err != nil {
+			return metadata, err
+		}
+	}
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return metadata, err
+	}
+	return metadata, os.WriteFile(targets[FetchMetadataFile], content, 0o644)
+}
+
+// layerFileName returns the file path a layer was published from, relative to project directory
+func layerFileName(layer v1.Descriptor) string {
+	if path, ok := layer.Annotations[ocipush.ComposePathAnnotation]; ok {
+		return path
+	}
+	return layer.Annotations[ocipush.ComposeFileAnnotation]
+}
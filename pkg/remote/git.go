@@ -18,22 +18,39 @@ package remote
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/moby/buildkit/util/gitutil"
 )
 
 const GIT_REMOTE_ENABLED = "COMPOSE_EXPERIMENTAL_GIT_REMOTE"
 
+// GIT_REMOTE_SSH_KEY selects the SSH private key used to access git remote resources.
+// Can also be set by `git-ssh-key` in the `compose` plugin section of docker config
+const GIT_REMOTE_SSH_KEY = "COMPOSE_GIT_SSH_KEY"
+
+// GIT_REMOTE_REFRESH_INTERVAL sets how long a floating git ref (branch, tag) resolves to the same commit before
+// compose queries the remote again. Can also be set by `git-refresh-interval` in the `compose` plugin section of docker config
+const GIT_REMOTE_REFRESH_INTERVAL = "COMPOSE_GIT_REFRESH_INTERVAL"
+
 func gitRemoteLoaderEnabled() (bool, error) {
 	if v := os.Getenv(GIT_REMOTE_ENABLED); v != "" {
 		enabled, err := strconv.ParseBool(v)
@@ -45,16 +62,30 @@ func gitRemoteLoaderEnabled() (bool, error) {
 	return false, nil
 }
 
-func NewGitRemoteLoader(offline bool) loader.ResourceLoader {
+// pluginConfig returns a setting from environment, or from the compose plugin section of docker config
+func pluginConfig(dockerCli command.Cli, env string, key string) string {
+	if v, ok := os.LookupEnv(env); ok {
+		return v
+	}
+	if dockerCli == nil || dockerCli.ConfigFile() == nil {
+		return ""
+	}
+	return dockerCli.ConfigFile().Plugins["compose"][key]
+}
+
+func NewGitRemoteLoader(dockerCli command.Cli, offline bool) loader.ResourceLoader {
 	return gitRemoteLoader{
-		offline: offline,
-		known:   map[string]string{},
+		dockerCli: dockerCli,
+		offline:   offline,
+		known:     map[string]string{},
 	}
 }
 
 type gitRemoteLoader struct {
-	offline bool
-	known   map[string]string
+	dockerCli command.Cli
+	offline   bool
+	refresh   bool
+	known     map[string]string
 }
 
 func (g gitRemoteLoader) Accept(path string) bool {
@@ -84,25 +115,29 @@ func (g gitRemoteLoader) Load(ctx context.Context, path string) (string, error)
 			ref.Commit = "HEAD" // default branch
 		}
 
-		err = g.resolveGitRef(ctx, path, ref)
+		cache, err := cacheDir()
 		if err != nil {
-			return "", err
+			return "", fmt.Errorf("initializing remote resource cache: %w", err)
 		}
 
-		cache, err := cacheDir()
+		err = g.resolveGitRef(ctx, cache, path, ref)
 		if err != nil {
-			return "", fmt.Errorf("initializing remote resource cache: %w", err)
+			return "", err
 		}
 
-		local = filepath.Join(cache, ref.Commit)
-		if _, err := os.Stat(local); os.IsNotExist(err) {
-			if g.offline {
-				return "", nil
-			}
-			err = g.checkout(ctx, local, ref)
-			if err != nil {
-				return "", err
-			}
+		key := gitCacheKey(ref)
+		local = filepath.Join(cache, key)
+		if _, err := os.Stat(local); os.IsNotExist(err) && g.offline {
+			return "", fmt.Errorf("%s is not available in cache while offline", path)
+		}
+		local, err = populateCacheEntry(ctx, cache, key, g.refresh, func(dir string) error {
+			return g.checkout(ctx, dir, ref)
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := recordCacheUse(cache, key, CacheEntryGit, path); err != nil {
+			return "", err
 		}
 		g.known[path] = local
 	}
@@ -123,26 +158,83 @@ func (g gitRemoteLoader) Dir(path string) string {
 	return g.known[path]
 }
 
-func (g gitRemoteLoader) resolveGitRef(ctx context.Context, path string, ref *gitutil.GitRef) error {
-	if !commitSHA.MatchString(ref.Commit) {
-		cmd := exec.CommandContext(ctx, "git", "ls-remote", "--exit-code", ref.Remote, ref.Commit)
-		cmd.Env = g.gitCommandEnv()
-		out, err := cmd.Output()
-		if err != nil {
-			if cmd.ProcessState.ExitCode() == 2 {
-				return fmt.Errorf("repository does not contain ref %s, output: %q: %w", path, string(out), err)
-			}
+// gitCacheKey returns the cache key for a checkout. As checkout is sparse when a sub-directory is set, distinct
+// sub-directories of the same commit get distinct cache entries
+func gitCacheKey(ref *gitutil.GitRef) string {
+	if ref.SubDir == "" {
+		return ref.Commit
+	}
+	sum := sha256.Sum256([]byte(ref.SubDir))
+	return ref.Commit + "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// gitResolvedRef records the commit a floating git ref resolved to
+type gitResolvedRef struct {
+	Commit   string    `json:"commit"`
+	Resolved time.Time `json:"resolved"`
+}
+
+func resolvedRefFile(cache string, ref *gitutil.GitRef) string {
+	sum := sha256.Sum256([]byte(ref.Remote + "#" + ref.Commit))
+	return filepath.Join(cache, ".refs", hex.EncodeToString(sum[:])+".json")
+}
+
+func (g gitRemoteLoader) resolveGitRef(ctx context.Context, cache string, path string, ref *gitutil.GitRef) error {
+	if commitSHA.MatchString(ref.Commit) {
+		return nil
+	}
+
+	interval, err := g.refreshInterval()
+	if err != nil {
+		return err
+	}
+	refFile := resolvedRefFile(cache, ref)
+	if !g.refresh && (interval > 0 || g.offline) {
+		var resolved gitResolvedRef
+		content, err := os.ReadFile(refFile)
+		if err == nil {
+			err = json.Unmarshal(content, &resolved)
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
 			return err
 		}
-		if len(out) < 40 {
-			return fmt.Errorf("unexpected git command output: %q", string(out))
+		if commitSHA.MatchString(resolved.Commit) && (g.offline || time.Since(resolved.Resolved) < interval) {
+			ref.Commit = resolved.Commit
+			return nil
 		}
-		sha := string(out[:40])
-		if !commitSHA.MatchString(sha) {
-			return fmt.Errorf("invalid commit sha %q", sha)
+	}
+	if g.offline {
+		return fmt.Errorf("%s is not available in cache while offline", path)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--exit-code", ref.Remote, ref.Commit)
+	cmd.Env = g.gitCommandEnv(ref)
+	out, err := cmd.Output()
+	if err != nil {
+		if cmd.ProcessState.ExitCode() == 2 {
+			return fmt.Errorf("repository does not contain ref %s, output: %q: %w", path, string(out), err)
 		}
-		ref.Commit = sha
+		return err
+	}
+	if len(out) < 40 {
+		return fmt.Errorf("unexpected git command output: %q", string(out))
 	}
+	sha := string(out[:40])
+	if !commitSHA.MatchString(sha) {
+		return fmt.Errorf("invalid commit sha %q", sha)
+	}
+
+	content, err := json.Marshal(gitResolvedRef{Commit: sha, Resolved: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(refFile), 0o700); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(refFile, content); err != nil {
+		return err
+	}
+	ref.Commit = sha
 	return nil
 }
 
@@ -151,36 +243,65 @@ func (g gitRemoteLoader) checkout(ctx context.Context, path string, ref *gitutil
 	if err != nil {
 		return err
 	}
-	err = exec.CommandContext(ctx, "git", "init", path).Run()
+	env := g.gitCommandEnv(ref)
+	git := func(args ...string) error {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Env = env
+		cmd.Dir = path
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("git %s: %w: %s", args[0], err, out)
+		}
+		return nil
+	}
+
+	err = git("init", "--quiet")
 	if err != nil {
 		return err
 	}
-
-	cmd := exec.CommandContext(ctx, "git", "remote", "add", "origin", ref.Remote)
-	cmd.Dir = path
-	err = cmd.Run()
+	err = git("remote", "add", "origin", ref.Remote)
 	if err != nil {
 		return err
 	}
 
-	cmd = exec.CommandContext(ctx, "git", "fetch", "--depth=1", "origin", ref.Commit)
-	cmd.Env = g.gitCommandEnv()
-	cmd.Dir = path
-	err = cmd.Run()
+	fetch := []string{"fetch", "--quiet", "--depth=1", "origin", ref.Commit}
+	if ref.SubDir != "" {
+		// partial clone, so only blobs for the sparse checkout are downloaded
+		err = git("config", "remote.origin.promisor", "true")
+		if err != nil {
+			return err
+		}
+		err = git("config", "remote.origin.partialclonefilter", "blob:none")
+		if err != nil {
+			return err
+		}
+		err = git("sparse-checkout", "set", ref.SubDir)
+		if err != nil {
+			return err
+		}
+		fetch = append(fetch, "--filter=blob:none")
+	}
+	err = git(fetch...)
 	if err != nil {
 		return err
 	}
 
-	cmd = exec.CommandContext(ctx, "git", "checkout", ref.Commit)
-	cmd.Dir = path
-	err = cmd.Run()
+	return git("checkout", "--quiet", ref.Commit)
+}
+
+func (g gitRemoteLoader) refreshInterval() (time.Duration, error) {
+	v := pluginConfig(g.dockerCli, GIT_REMOTE_REFRESH_INTERVAL, "git-refresh-interval")
+	if v == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(v)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("invalid git refresh interval %q: %w", v, err)
 	}
-	return nil
+	return interval, nil
 }
 
-func (g gitRemoteLoader) gitCommandEnv() []string {
+func (g gitRemoteLoader) gitCommandEnv(ref *gitutil.GitRef) []string {
 	env := types.NewMapping(os.Environ())
 	if env["GIT_TERMINAL_PROMPT"] == "" {
 		// Disable prompting for passwords by Git until user explicitly asks for it.
@@ -189,11 +310,50 @@ func (g gitRemoteLoader) gitCommandEnv() []string {
 	if env["GIT_SSH"] == "" && env["GIT_SSH_COMMAND"] == "" {
 		// Disable any ssh connection pooling by Git and do not attempt to prompt the user.
 		env["GIT_SSH_COMMAND"] = "ssh -o ControlMaster=no -o BatchMode=yes"
+		if key := pluginConfig(g.dockerCli, GIT_REMOTE_SSH_KEY, "git-ssh-key"); key != "" {
+			env["GIT_SSH_COMMAND"] += " -o IdentitiesOnly=yes -i " + strconv.Quote(key)
+		}
+	}
+	if header := g.authorizationHeader(ref); header != "" {
+		// Pass credentials by environment, so they don't show up in process list.
+		// See https://git-scm.com/docs/git-config#Documentation/git-config.txt-GITCONFIGCOUNT
+		count, _ := strconv.Atoi(env["GIT_CONFIG_COUNT"])
+		env[fmt.Sprintf("GIT_CONFIG_KEY_%d", count)] = fmt.Sprintf("http.%s.extraHeader", ref.Remote)
+		env[fmt.Sprintf("GIT_CONFIG_VALUE_%d", count)] = header
+		env["GIT_CONFIG_COUNT"] = strconv.Itoa(count + 1)
 	}
 	v := env.Values()
 	return v
 }
 
+// authorizationHeader returns an HTTP authorization header for an HTTPS git remote, using credentials set in
+// docker config (or credential helpers) for the remote host
+func (g gitRemoteLoader) authorizationHeader(ref *gitutil.GitRef) string {
+	if g.dockerCli == nil || g.dockerCli.ConfigFile() == nil {
+		return ""
+	}
+	u, err := url.Parse(ref.Remote)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return ""
+	}
+	auth, err := g.dockerCli.ConfigFile().GetAuthConfig(u.Host)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case auth.RegistryToken != "":
+		return "Authorization: Bearer " + auth.RegistryToken
+	case auth.Password != "":
+		username := auth.Username
+		if username == "" {
+			username = "oauth2"
+		}
+		return "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+auth.Password))
+	default:
+		return ""
+	}
+}
+
 func findFile(names []string, pwd string) (string, error) {
 	for _, n := range names {
 		f := filepath.Join(pwd, n)
@@ -0,0 +1,226 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	clitypes "github.com/docker/cli/cli/config/types"
+	"github.com/moby/buildkit/util/gitutil"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/mocks"
+)
+
+// gitRepository creates a local git repository with files committed, and returns its file URL
+func gitRepository(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main")
+	runGit(t, dir, "config", "uploadpack.allowFilter", "true")
+	runGit(t, dir, "config", "uploadpack.allowAnySHA1InWant", "true")
+	commitFiles(t, dir, files)
+	return "file://" + filepath.ToSlash(dir)
+}
+
+func commitFiles(t *testing.T, dir string, files map[string]string) string {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "test")
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.NilError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitCacheKey(t *testing.T) {
+	commit := "0123456789abcdef0123456789abcdef01234567"
+	root := gitCacheKey(&gitutil.GitRef{Commit: commit})
+	assert.Equal(t, root, commit)
+	assert.Assert(t, isCacheKey(root))
+
+	app := gitCacheKey(&gitutil.GitRef{Commit: commit, SubDir: "app"})
+	assert.Assert(t, isCacheKey(app))
+	assert.Assert(t, strings.HasPrefix(app, commit+"-"))
+	assert.Equal(t, app, gitCacheKey(&gitutil.GitRef{Commit: commit, SubDir: "app"}))
+	assert.Assert(t, app != gitCacheKey(&gitutil.GitRef{Commit: commit, SubDir: "db"}))
+}
+
+func TestGitAuthorizationHeader(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	cli := mocks.NewMockCli(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{
+		AuthConfigs: map[string]clitypes.AuthConfig{
+			"token.example.com":    {RegistryToken: "secret-token"},
+			"password.example.com": {Username: "jdoe", Password: "secret"},
+			"oauth.example.com":    {Password: "secret"},
+		},
+	}).AnyTimes()
+	g := gitRemoteLoader{dockerCli: cli}
+
+	basic := func(credentials string) string {
+		return "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+	tests := []struct {
+		remote   string
+		expected string
+	}{
+		{remote: "https://token.example.com/org/repo.git", expected: "Authorization: Bearer secret-token"},
+		{remote: "https://password.example.com/org/repo.git", expected: basic("jdoe:secret")},
+		{remote: "https://oauth.example.com/org/repo.git", expected: basic("oauth2:secret")},
+		{remote: "https://jdoe@password.example.com/org/repo.git"},
+		{remote: "https://unknown.example.com/org/repo.git"},
+		{remote: "git@password.example.com:org/repo.git"},
+		{remote: "ssh://password.example.com/org/repo.git"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			assert.Equal(t, g.authorizationHeader(&gitutil.GitRef{Remote: tt.remote}), tt.expected)
+		})
+	}
+}
+
+func TestGitCommandEnvAuthorization(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	cli := mocks.NewMockCli(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{
+		AuthConfigs: map[string]clitypes.AuthConfig{
+			"example.com": {RegistryToken: "secret-token"},
+		},
+	}).AnyTimes()
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "core.autocrlf")
+	t.Setenv("GIT_CONFIG_VALUE_0", "false")
+
+	env := gitRemoteLoader{dockerCli: cli}.gitCommandEnv(&gitutil.GitRef{Remote: "https://example.com/repo.git"})
+	assert.Assert(t, contains(env, "GIT_CONFIG_COUNT=2"))
+	assert.Assert(t, contains(env, "GIT_CONFIG_KEY_0=core.autocrlf"))
+	assert.Assert(t, contains(env, "GIT_CONFIG_KEY_1=http.https://example.com/repo.git.extraHeader"))
+	assert.Assert(t, contains(env, "GIT_CONFIG_VALUE_1=Authorization: Bearer secret-token"))
+}
+
+func contains(env []string, value string) bool {
+	for _, e := range env {
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGitRefreshInterval(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	cli := mocks.NewMockCli(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{
+		Plugins: map[string]map[string]string{
+			"compose": {"git-refresh-interval": "1h"},
+		},
+	}).AnyTimes()
+	g := gitRemoteLoader{dockerCli: cli}
+
+	interval, err := gitRemoteLoader{}.refreshInterval()
+	assert.NilError(t, err)
+	assert.Equal(t, interval.String(), "0s")
+
+	interval, err = g.refreshInterval()
+	assert.NilError(t, err)
+	assert.Equal(t, interval.String(), "1h0m0s")
+
+	t.Setenv(GIT_REMOTE_REFRESH_INTERVAL, "15m")
+	interval, err = g.refreshInterval()
+	assert.NilError(t, err)
+	assert.Equal(t, interval.String(), "15m0s")
+
+	t.Setenv(GIT_REMOTE_REFRESH_INTERVAL, "daily")
+	_, err = g.refreshInterval()
+	assert.ErrorContains(t, err, `invalid git refresh interval "daily"`)
+}
+
+func TestGitResolveRef(t *testing.T) {
+	cache := testCacheDir(t)
+	ctx := context.Background()
+	remote := gitRepository(t, map[string]string{"compose.yaml": "services: {}"})
+	repo := strings.TrimPrefix(remote, "file://")
+	first := runGit(t, repo, "rev-parse", "HEAD")
+
+	resolve := func(g gitRemoteLoader) (string, error) {
+		ref := &gitutil.GitRef{Remote: remote, Commit: "main"}
+		err := g.resolveGitRef(ctx, cache, remote+"#main", ref)
+		return ref.Commit, err
+	}
+
+	_, err := resolve(gitRemoteLoader{offline: true})
+	assert.ErrorContains(t, err, "not available in cache while offline")
+
+	commit, err := resolve(gitRemoteLoader{})
+	assert.NilError(t, err)
+	assert.Equal(t, commit, first)
+
+	second := commitFiles(t, repo, map[string]string{"compose.yaml": "services: {web: {image: nginx}}"})
+
+	t.Setenv(GIT_REMOTE_REFRESH_INTERVAL, "1h")
+	commit, err = resolve(gitRemoteLoader{})
+	assert.NilError(t, err)
+	assert.Equal(t, commit, first, "ref resolution should be reused within refresh interval")
+
+	commit, err = resolve(gitRemoteLoader{offline: true})
+	assert.NilError(t, err)
+	assert.Equal(t, commit, first, "ref resolution should be reused while offline")
+
+	commit, err = resolve(gitRemoteLoader{refresh: true})
+	assert.NilError(t, err)
+	assert.Equal(t, commit, second)
+
+	t.Setenv(GIT_REMOTE_REFRESH_INTERVAL, "")
+	commit, err = resolve(gitRemoteLoader{})
+	assert.NilError(t, err)
+	assert.Equal(t, commit, second)
+}
+
+func TestGitSparseCheckout(t *testing.T) {
+	remote := gitRepository(t, map[string]string{
+		"app/compose.yaml": "services: {}",
+		"db/compose.yaml":  "services: {}",
+		"README.md":        "test",
+	})
+	commit := runGit(t, strings.TrimPrefix(remote, "file://"), "rev-parse", "HEAD")
+
+	dir := t.TempDir()
+	err := gitRemoteLoader{}.checkout(context.Background(), dir, &gitutil.GitRef{Remote: remote, Commit: commit, SubDir: "app"})
+	assert.NilError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "app", "compose.yaml"))
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "db"))
+	assert.Assert(t, os.IsNotExist(err), "sub-directory outside of sparse checkout should not be checked out")
+}
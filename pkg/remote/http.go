@@ -0,0 +1,280 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/moby/buildkit/util/gitutil"
+)
+
+const HTTP_REMOTE_ENABLED = "COMPOSE_EXPERIMENTAL_HTTP_REMOTE"
+
+func httpRemoteLoaderEnabled() (bool, error) {
+	if v := os.Getenv(HTTP_REMOTE_ENABLED); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("COMPOSE_EXPERIMENTAL_HTTP_REMOTE environment variable expects boolean value: %w", err)
+		}
+		return enabled, err
+	}
+	return false, nil
+}
+
+// NewHTTPRemoteLoader creates a loader for compose files published at an HTTPS URL. An optional `#sha256=<digest>`
+// fragment pins the expected content
+func NewHTTPRemoteLoader(offline bool) loader.ResourceLoader {
+	return httpRemoteLoader{
+		offline: offline,
+		client:  http.DefaultClient,
+		known:   map[string]string{},
+	}
+}
+
+type httpRemoteLoader struct {
+	offline bool
+	refresh bool
+	client  *http.Client
+	known   map[string]string
+}
+
+// httpCacheValidators are the response headers used to revalidate a cached file, stored next to it
+type httpCacheValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+const httpValidatorsFile = ".http-cache.json"
+
+func (h httpRemoteLoader) Accept(path string) bool {
+	if !strings.HasPrefix(path, "https://") {
+		return false
+	}
+	// git repositories are managed by gitRemoteLoader
+	_, err := gitutil.ParseGitRef(path)
+	return err != nil
+}
+
+func (h httpRemoteLoader) Load(ctx context.Context, path string) (string, error) {
+	enabled, err := httpRemoteLoaderEnabled()
+	if err != nil {
+		return "", err
+	}
+	if !enabled {
+		return "", fmt.Errorf("experimental HTTP remote resource is disabled. %q must be set", HTTP_REMOTE_ENABLED)
+	}
+
+	if local, ok := h.known[path]; ok {
+		return local, nil
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	pinned, err := pinnedDigest(u.Fragment)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", path, err)
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	cache, err := cacheDir()
+	if err != nil {
+		return "", fmt.Errorf("initializing remote resource cache: %w", err)
+	}
+	sum := sha256.Sum256([]byte(u.String()))
+	key := hex.EncodeToString(sum[:])
+
+	unlock, err := lockCacheEntry(ctx, cache, key)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	dir := filepath.Join(cache, key)
+	local := filepath.Join(dir, httpFileName(u))
+	content, err := os.ReadFile(local)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	cached := err == nil
+
+	switch {
+	case cached && pinned != "" && sha256Hex(content) == pinned:
+		// content is immutable, no need to revalidate
+	case h.offline:
+		if !cached {
+			return "", fmt.Errorf("%s is not available in cache while offline", u.Redacted())
+		}
+	default:
+		content, err = h.download(ctx, u, dir, local, pinned, cached && !h.refresh)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := checkPinnedDigest(u, content, pinned); err != nil {
+		return "", err
+	}
+	if err := recordCacheUse(cache, key, CacheEntryHTTP, path); err != nil {
+		return "", err
+	}
+	h.known[path] = local
+	return local, nil
+}
+
+func (h httpRemoteLoader) Dir(path string) string {
+	local, ok := h.known[path]
+	if !ok {
+		return ""
+	}
+	return filepath.Dir(local)
+}
+
+// download fetches u into local file, revalidating cached content with ETag and Last-Modified when revalidate is set.
+// Content not matching pinned digest is not written to cache
+func (h httpRemoteLoader) download(ctx context.Context, u *url.URL, dir string, local string, pinned string, revalidate bool) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	validatorsFile := filepath.Join(dir, httpValidatorsFile)
+	if revalidate {
+		var validators httpCacheValidators
+		if b, err := os.ReadFile(validatorsFile); err == nil {
+			if err := json.Unmarshal(b, &validators); err != nil {
+				return nil, err
+			}
+		}
+		if validators.ETag != "" {
+			req.Header.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && revalidate:
+		return os.ReadFile(local)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to download %s: %s", u.Redacted(), resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPinnedDigest(u, content, pinned); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(local, content); err != nil {
+		return nil, err
+	}
+	validators, err := json.Marshal(httpCacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return content, writeFileAtomic(validatorsFile, validators)
+}
+
+// pinnedDigest parses a `sha256=<digest>` URL fragment
+func pinnedDigest(fragment string) (string, error) {
+	if fragment == "" {
+		return "", nil
+	}
+	digest, ok := strings.CutPrefix(fragment, "sha256=")
+	if !ok {
+		return "", fmt.Errorf("unsupported fragment %q, expected sha256=<digest>", fragment)
+	}
+	digest = strings.ToLower(digest)
+	if len(digest) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid sha256 digest %q", digest)
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", fmt.Errorf("invalid sha256 digest %q", digest)
+	}
+	return digest, nil
+}
+
+// checkPinnedDigest verifies content matches the digest pinned by URL fragment, if any
+func checkPinnedDigest(u *url.URL, content []byte, pinned string) error {
+	if pinned != "" && sha256Hex(content) != pinned {
+		return fmt.Errorf("content of %s doesn't match sha256 %s", u.Redacted(), pinned)
+	}
+	return nil
+}
+
+// httpFileName returns the name of the downloaded file in cache, so compose can detect its format from extension
+func httpFileName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "" || name == "." || name == "/" || name == ".." || name == httpValidatorsFile {
+		return "compose.yaml"
+	}
+	return name
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeFileAtomic(file string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+var _ loader.ResourceLoader = httpRemoteLoader{}
@@ -0,0 +1,134 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const httpTestCompose = "services:\n  foo:\n    image: nginx\n"
+
+type httpTestServer struct {
+	*httptest.Server
+	requests    int
+	notModified int
+}
+
+func newHTTPTestServer(t *testing.T) *httpTestServer {
+	s := &httpTestServer{}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		if r.URL.Path != "/compose.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(httpTestCompose))
+	}))
+	t.Cleanup(s.Close)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv(HTTP_REMOTE_ENABLED, "true")
+	return s
+}
+
+func (s *httpTestServer) loader(offline bool) httpRemoteLoader {
+	return httpRemoteLoader{
+		offline: offline,
+		client:  s.Client(),
+		known:   map[string]string{},
+	}
+}
+
+func TestHTTPRemoteLoaderAccept(t *testing.T) {
+	h := httpRemoteLoader{}
+	assert.Check(t, h.Accept("https://example.com/compose.yaml"))
+	assert.Check(t, h.Accept("https://example.com/compose.yaml#sha256=abc"))
+	assert.Check(t, !h.Accept("http://example.com/compose.yaml"))
+	assert.Check(t, !h.Accept("https://example.com/repo.git"))
+	assert.Check(t, !h.Accept("oci://example.com/project"))
+}
+
+func TestHTTPRemoteLoaderRevalidate(t *testing.T) {
+	server := newHTTPTestServer(t)
+	ctx := context.Background()
+
+	local, err := server.loader(false).Load(ctx, server.URL+"/compose.yaml")
+	assert.NilError(t, err)
+	content, err := os.ReadFile(local)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), httpTestCompose)
+
+	again, err := server.loader(false).Load(ctx, server.URL+"/compose.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, again, local)
+	assert.Equal(t, server.requests, 2)
+	assert.Equal(t, server.notModified, 1)
+
+	_, err = server.loader(false).Load(ctx, server.URL+"/missing.yaml")
+	assert.ErrorContains(t, err, "404 Not Found")
+}
+
+func TestHTTPRemoteLoaderChecksum(t *testing.T) {
+	server := newHTTPTestServer(t)
+	ctx := context.Background()
+
+	_, err := server.loader(false).Load(ctx, server.URL+"/compose.yaml#sha256="+sha256Hex([]byte("something else")))
+	assert.ErrorContains(t, err, "doesn't match sha256")
+	// content not matching pinned digest is not cached
+	_, err = server.loader(true).Load(ctx, server.URL+"/compose.yaml")
+	assert.ErrorContains(t, err, "not available in cache while offline")
+
+	_, err = server.loader(false).Load(ctx, server.URL+"/compose.yaml#md5=abc")
+	assert.ErrorContains(t, err, "unsupported fragment")
+
+	pinned := server.URL + "/compose.yaml#sha256=" + sha256Hex([]byte(httpTestCompose))
+	_, err = server.loader(false).Load(ctx, pinned)
+	assert.NilError(t, err)
+	requests := server.requests
+
+	// pinned content in cache is not revalidated
+	_, err = server.loader(false).Load(ctx, pinned)
+	assert.NilError(t, err)
+	assert.Equal(t, server.requests, requests)
+}
+
+func TestHTTPRemoteLoaderOffline(t *testing.T) {
+	server := newHTTPTestServer(t)
+	ctx := context.Background()
+
+	_, err := server.loader(true).Load(ctx, server.URL+"/compose.yaml")
+	assert.ErrorContains(t, err, "not available in cache while offline")
+
+	local, err := server.loader(false).Load(ctx, server.URL+"/compose.yaml")
+	assert.NilError(t, err)
+
+	offline, err := server.loader(true).Load(ctx, server.URL+"/compose.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, offline, local)
+	assert.Equal(t, server.requests, 1)
+}
@@ -30,7 +30,10 @@ import (
 	"github.com/docker/buildx/store/storeutil"
 	"github.com/docker/buildx/util/imagetools"
 	"github.com/docker/cli/cli/command"
+	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/docker/compose/v2/internal/ocipush"
 )
 
 const OCI_REMOTE_ENABLED = "COMPOSE_EXPERIMENTAL_OCI_REMOTE"
@@ -57,6 +60,7 @@ func NewOCIRemoteLoader(dockerCli command.Cli, offline bool) loader.ResourceLoad
 type ociRemoteLoader struct {
 	dockerCli command.Cli
 	offline   bool
+	refresh   bool
 	known     map[string]string
 }
 
@@ -92,29 +96,33 @@ func (g ociRemoteLoader) Load(ctx context.Context, path string) (string, error)
 		}
 		resolver := imagetools.New(opt)
 
-		content, descriptor, err := resolver.Get(ctx, ref.String())
+		manifest, descriptor, err := getComposeManifest(ctx, resolver, ref)
+		if err != nil {
+			return "", err
+		}
+
+		verifier, err := newSignatureVerifier(g.dockerCli)
 		if err != nil {
 			return "", err
 		}
+		if err := verifier.verify(ctx, opt, ref, descriptor.Digest); err != nil {
+			return "", err
+		}
 
 		cache, err := cacheDir()
 		if err != nil {
 			return "", fmt.Errorf("initializing remote resource cache: %w", err)
 		}
 
-		local = filepath.Join(cache, descriptor.Digest.Hex())
-		composeFile := filepath.Join(local, "compose.yaml")
-		if _, err = os.Stat(local); os.IsNotExist(err) {
-			var manifest v1.Manifest
-			err = json.Unmarshal(content, &manifest)
-			if err != nil {
-				return "", err
-			}
-
-			err2 := g.pullComposeFiles(ctx, local, composeFile, manifest, ref, resolver)
-			if err2 != nil {
-				return "", err2
-			}
+		key := descriptor.Digest.Hex()
+		local, err = populateCacheEntry(ctx, cache, key, g.refresh, func(dir string) error {
+			return g.pullComposeFiles(ctx, dir, filepath.Join(dir, "compose.yaml"), manifest, ref, resolver)
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := recordCacheUse(cache, key, CacheEntryOCI, path); err != nil {
+			return "", err
 		}
 		g.known[path] = local
 	}
@@ -138,25 +146,34 @@ func (g ociRemoteLoader) pullComposeFiles(ctx context.Context, local string, com
 	}
 	defer f.Close() //nolint:errcheck
 
-	if manifest.ArtifactType != "application/vnd.docker.compose.project" {
-		return fmt.Errorf("%s is not a compose project OCI artifact, but %s", ref.String(), manifest.ArtifactType)
+	if err := validateComposeManifest(ref, manifest); err != nil {
+		return err
 	}
 
-	for i, layer := range manifest.Layers {
-		digested, err := reference.WithDigest(ref, layer.Digest)
+	var composeLayers int
+	for _, layer := range manifest.Layers {
+		content, err := getBlob(ctx, resolver, reference.TrimNamed(ref), layer)
 		if err != nil {
 			return err
 		}
-		content, _, err := resolver.Get(ctx, digested.String())
-		if err != nil {
-			return err
+
+		switch layer.MediaType {
+		case ocipush.ComposeEnvFileMediaType, ocipush.ComposeResourceMediaType:
+			// restore project layout so that relative paths in compose files resolve
+			err = writeResourceFile(local, layer.Annotations[ocipush.ComposePathAnnotation], content)
+			if err != nil {
+				return err
+			}
+			continue
 		}
-		if i > 0 {
+
+		if composeLayers > 0 {
 			_, err = f.Write([]byte("\n---\n"))
 			if err != nil {
 				return err
 			}
 		}
+		composeLayers++
 		_, err = f.Write(content)
 		if err != nil {
 			return err
@@ -165,4 +182,75 @@ func (g ociRemoteLoader) pullComposeFiles(ctx context.Context, local string, com
 	return nil
 }
 
+// getComposeManifest fetches the manifest ref points to, and checks its content matches the digest it was resolved
+// to, as signature is verified against this digest
+func getComposeManifest(ctx context.Context, resolver *imagetools.Resolver, ref reference.Named) (v1.Manifest, v1.Descriptor, error) {
+	var manifest v1.Manifest
+	content, descriptor, err := resolver.Get(ctx, ref.String())
+	if err != nil {
+		return manifest, descriptor, err
+	}
+	if digest.FromBytes(content) != descriptor.Digest {
+		return manifest, descriptor, fmt.Errorf("content of %s doesn't match its digest %s", ref.String(), descriptor.Digest)
+	}
+	err = json.Unmarshal(content, &manifest)
+	return manifest, descriptor, err
+}
+
+// writeResourceFile writes a file published with project at its path relative to project directory
+func writeResourceFile(local string, path string, content []byte) error {
+	if path == "" {
+		return fmt.Errorf("published resource is missing %s annotation", ocipush.ComposePathAnnotation)
+	}
+	target, err := projectFilePath(local, path)
+	if err != nil || target == filepath.Join(local, "compose.yaml") {
+		return fmt.Errorf("invalid path for published resource: %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(target, content, 0o600)
+}
+
+// projectFilePath resolves a file path set by a layer annotation inside dir, refusing paths escaping it
+func projectFilePath(dir string, path string) (string, error) {
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid file path %q", path)
+	}
+	target := filepath.Join(dir, filepath.FromSlash(path))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid file path %q", path)
+	}
+	return target, nil
+}
+
+// validateComposeManifest checks manifest is a compose project artifact, either pushed as OCI 1.1 artifact or
+// with the OCI 1.0 compatible config media type
+func validateComposeManifest(ref reference.Named, manifest v1.Manifest) error {
+	if manifest.MediaType != "" && manifest.MediaType != v1.MediaTypeImageManifest {
+		return fmt.Errorf("%s is not an OCI image manifest, but %s", ref.String(), manifest.MediaType)
+	}
+	switch {
+	case manifest.ArtifactType == ocipush.ComposeProjectArtifactType:
+		if manifest.Config.MediaType != v1.MediaTypeEmptyJSON {
+			return fmt.Errorf("%s has unexpected config media type %s", ref.String(), manifest.Config.MediaType)
+		}
+	case manifest.ArtifactType == "" && manifest.Config.MediaType == ocipush.ComposeEmptyConfigMediaType:
+		// OCI 1.0 compatible artifact
+	case manifest.ArtifactType == "":
+		return fmt.Errorf("%s is not a compose project OCI artifact, but has config media type %s", ref.String(), manifest.Config.MediaType)
+	default:
+		return fmt.Errorf("%s is not a compose project OCI artifact, but %s", ref.String(), manifest.ArtifactType)
+	}
+	for _, layer := range manifest.Layers {
+		switch layer.MediaType {
+		case ocipush.ComposeYAMLMediaType, ocipush.ComposeEnvFileMediaType, ocipush.ComposeResourceMediaType:
+		default:
+			return fmt.Errorf("%s has unexpected layer media type %s", ref.String(), layer.MediaType)
+		}
+	}
+	return nil
+}
+
 var _ loader.ResourceLoader = ociRemoteLoader{}
@@ -0,0 +1,203 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/buildx/util/resolver"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/internal/ocipush"
+)
+
+func TestProjectFilePath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "compose.yaml", expected: filepath.Join(dir, "compose.yaml")},
+		{path: "infra/db.env", expected: filepath.Join(dir, "infra", "db.env")},
+		{path: "infra/../app.env", expected: filepath.Join(dir, "app.env")},
+		{path: "./config/nginx.conf", expected: filepath.Join(dir, "config", "nginx.conf")},
+		{path: "."},
+		{path: ""},
+		{path: "infra/.."},
+		{path: ".."},
+		{path: "../outside.env"},
+		{path: "infra/../../outside.env"},
+		{path: "/etc/passwd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			target, err := projectFilePath(dir, tt.path)
+			if tt.expected == "" {
+				assert.ErrorContains(t, err, "invalid file path")
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, target, tt.expected)
+		})
+	}
+}
+
+func TestValidateComposeManifest(t *testing.T) {
+	ref, err := reference.ParseDockerRef("example.com/user/project:latest")
+	assert.NilError(t, err)
+	composeLayer := v1.Descriptor{MediaType: ocipush.ComposeYAMLMediaType}
+
+	tests := []struct {
+		name     string
+		manifest v1.Manifest
+		err      string
+	}{
+		{
+			name: "OCI 1.1 artifact",
+			manifest: v1.Manifest{
+				MediaType:    v1.MediaTypeImageManifest,
+				ArtifactType: ocipush.ComposeProjectArtifactType,
+				Config:       v1.DescriptorEmptyJSON,
+				Layers: []v1.Descriptor{
+					composeLayer,
+					{MediaType: ocipush.ComposeEnvFileMediaType},
+					{MediaType: ocipush.ComposeResourceMediaType},
+				},
+			},
+		},
+		{
+			name: "OCI 1.0 artifact",
+			manifest: v1.Manifest{
+				MediaType: v1.MediaTypeImageManifest,
+				Config:    v1.Descriptor{MediaType: ocipush.ComposeEmptyConfigMediaType},
+				Layers:    []v1.Descriptor{composeLayer},
+			},
+		},
+		{
+			name: "image index",
+			manifest: v1.Manifest{
+				MediaType: v1.MediaTypeImageIndex,
+			},
+			err: "is not an OCI image manifest",
+		},
+		{
+			name: "OCI 1.1 artifact with unexpected config",
+			manifest: v1.Manifest{
+				ArtifactType: ocipush.ComposeProjectArtifactType,
+				Config:       v1.Descriptor{MediaType: v1.MediaTypeImageConfig},
+				Layers:       []v1.Descriptor{composeLayer},
+			},
+			err: "unexpected config media type",
+		},
+		{
+			name: "container image",
+			manifest: v1.Manifest{
+				Config: v1.Descriptor{MediaType: v1.MediaTypeImageConfig},
+				Layers: []v1.Descriptor{{MediaType: v1.MediaTypeImageLayerGzip}},
+			},
+			err: "is not a compose project OCI artifact, but has config media type",
+		},
+		{
+			name: "other artifact type",
+			manifest: v1.Manifest{
+				ArtifactType: "application/vnd.cncf.helm.config.v1+json",
+				Config:       v1.DescriptorEmptyJSON,
+			},
+			err: "is not a compose project OCI artifact, but application/vnd.cncf.helm.config.v1+json",
+		},
+		{
+			name: "unexpected layer",
+			manifest: v1.Manifest{
+				ArtifactType: ocipush.ComposeProjectArtifactType,
+				Config:       v1.DescriptorEmptyJSON,
+				Layers: []v1.Descriptor{
+					composeLayer,
+					{MediaType: v1.MediaTypeImageLayerGzip},
+				},
+			},
+			err: "unexpected layer media type " + v1.MediaTypeImageLayerGzip,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateComposeManifest(ref, tt.manifest)
+			if tt.err == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGetComposeManifestDigest(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[]}`)
+	tampered := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[{}]}`)
+	tests := []struct {
+		name    string
+		content []byte
+		err     string
+	}{
+		{name: "matching", content: manifest},
+		{name: "tampered", content: tampered, err: "doesn't match its digest " + digest.FromBytes(manifest).String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path != "/v2/org/project/manifests/latest" && req.URL.Path != "/v2/org/project/manifests/"+digest.FromBytes(manifest).String() {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				// registry advertises digest of the genuine manifest, but serves other content
+				w.Header().Set("Content-Type", v1.MediaTypeImageManifest)
+				w.Header().Set("Content-Length", strconv.Itoa(len(tt.content)))
+				w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+				if req.Method == http.MethodGet {
+					_, _ = w.Write(tt.content)
+				}
+			}))
+			t.Cleanup(registry.Close)
+			host := strings.TrimPrefix(registry.URL, "http://")
+			plainHTTP := true
+			r := imagetools.New(imagetools.Opt{
+				Auth:           &configfile.ConfigFile{},
+				RegistryConfig: map[string]resolver.RegistryConfig{host: {PlainHTTP: &plainHTTP}},
+			})
+			ref, err := reference.ParseDockerRef(host + "/org/project:latest")
+			assert.NilError(t, err)
+
+			_, descriptor, err := getComposeManifest(context.Background(), r, ref)
+			if tt.err == "" {
+				assert.NilError(t, err)
+				assert.Equal(t, descriptor.Digest, digest.FromBytes(manifest))
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
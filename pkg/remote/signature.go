@@ -0,0 +1,376 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/buildx/util/resolver"
+	"github.com/docker/cli/cli/command"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// OCI_REMOTE_PUBLIC_KEYS is a comma-separated list of PEM public key files trusted to sign compose OCI artifacts.
+// Can also be set by `oci-public-keys` in the `compose` plugin section of docker config
+const OCI_REMOTE_PUBLIC_KEYS = "COMPOSE_OCI_PUBLIC_KEYS"
+
+// OCI_REMOTE_SIGNATURE_POLICY sets how a compose OCI artifact signature is checked, either `enforce`, `warn` or `ignore`.
+// Default is to enforce a valid signature as soon as public keys are configured.
+// Can also be set by `oci-signature-policy` in the `compose` plugin section of docker config
+const OCI_REMOTE_SIGNATURE_POLICY = "COMPOSE_OCI_SIGNATURE_POLICY"
+
+const (
+	SignaturePolicyEnforce = "enforce"
+	SignaturePolicyWarn    = "warn"
+	SignaturePolicyIgnore  = "ignore"
+)
+
+const (
+	// cosignSignatureAnnotation is set on signature layers by cosign, with base64 encoded signature of layer content
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// cosignSignatureArtifactType is the artifact type of cosign signatures stored as OCI 1.1 referrers
+	cosignSignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// cosignSignatureType is the type of cosign simple signing payloads
+	cosignSignatureType = "cosign container image signature"
+)
+
+// simpleSigningPayload is the signed payload of a cosign signature
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+type signatureVerifier struct {
+	policy string
+	keys   []crypto.PublicKey
+}
+
+// newSignatureVerifier configures signature verification of compose OCI artifacts from environment or docker config
+func newSignatureVerifier(dockerCli command.Cli) (signatureVerifier, error) {
+	var v signatureVerifier
+	files := pluginConfig(dockerCli, OCI_REMOTE_PUBLIC_KEYS, "oci-public-keys")
+	for _, file := range strings.Split(files, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		key, err := loadPublicKey(file)
+		if err != nil {
+			return v, fmt.Errorf("loading OCI signature public key: %w", err)
+		}
+		v.keys = append(v.keys, key)
+	}
+
+	v.policy = pluginConfig(dockerCli, OCI_REMOTE_SIGNATURE_POLICY, "oci-signature-policy")
+	switch v.policy {
+	case "":
+		v.policy = SignaturePolicyIgnore
+		if len(v.keys) > 0 {
+			v.policy = SignaturePolicyEnforce
+		}
+	case SignaturePolicyEnforce, SignaturePolicyWarn:
+		if len(v.keys) == 0 {
+			return v, fmt.Errorf("OCI signature policy %q requires public keys to be set by %s", v.policy, OCI_REMOTE_PUBLIC_KEYS)
+		}
+	case SignaturePolicyIgnore:
+	default:
+		return v, fmt.Errorf("invalid OCI signature policy %q, expected one of %s, %s or %s", v.policy, SignaturePolicyEnforce, SignaturePolicyWarn, SignaturePolicyIgnore)
+	}
+	return v, nil
+}
+
+func loadPublicKey(file string) (crypto.PublicKey, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM encoded public key", file)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return key, nil
+}
+
+// verify checks the compose artifact with manifest digest dgst has a signature made by one of the configured keys.
+// Signatures are looked up as cosign does, as OCI 1.1 referrers using the referrers API or the referrers tag schema
+// when registry doesn't support it, or by `.sig` tag
+func (v signatureVerifier) verify(ctx context.Context, opt imagetools.Opt, ref reference.Named, dgst digest.Digest) error {
+	if v.policy == SignaturePolicyIgnore {
+		return nil
+	}
+	err := v.findSignature(ctx, opt, ref, dgst)
+	if err != nil && v.policy == SignaturePolicyWarn {
+		logrus.Warn(err.Error())
+		return nil
+	}
+	return err
+}
+
+func (v signatureVerifier) findSignature(ctx context.Context, opt imagetools.Opt, ref reference.Named, dgst digest.Digest) error {
+	resolver := imagetools.New(opt)
+	repository := reference.TrimNamed(ref)
+	tag := fmt.Sprintf("%s-%s", dgst.Algorithm(), dgst.Encoded())
+
+	index, supported, err := fetchReferrers(ctx, opt, repository, dgst, cosignSignatureArtifactType)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		content, _, err := resolver.Get(ctx, repository.String()+":"+tag)
+		switch {
+		case errdefs.IsNotFound(err):
+		case err != nil:
+			return fmt.Errorf("looking up signatures of %s@%s: %w", repository.String(), dgst, err)
+		default:
+			if err := json.Unmarshal(content, &index); err != nil {
+				return err
+			}
+		}
+	}
+
+	var manifests []v1.Manifest
+	for _, m := range index.Manifests {
+		if m.ArtifactType != cosignSignatureArtifactType {
+			continue
+		}
+		manifest, err := getManifest(ctx, resolver, repository, m)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	content, _, err := resolver.Get(ctx, repository.String()+":"+tag+".sig")
+	switch {
+	case errdefs.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("looking up signatures of %s@%s: %w", repository.String(), dgst, err)
+	default:
+		var manifest v1.Manifest
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return err
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	var invalid error
+	for _, manifest := range manifests {
+		for _, layer := range manifest.Layers {
+			signature, ok := layer.Annotations[cosignSignatureAnnotation]
+			if !ok {
+				continue
+			}
+			payload, err := getBlob(ctx, resolver, repository, layer)
+			if err != nil {
+				return err
+			}
+			err = verifySignature(v.keys, payload, signature, repository, dgst)
+			if err == nil {
+				return nil
+			}
+			invalid = err
+		}
+	}
+	if invalid != nil {
+		return fmt.Errorf("invalid signature for %s@%s: %w", repository.String(), dgst, invalid)
+	}
+	return fmt.Errorf("no signature found for %s@%s", repository.String(), dgst)
+}
+
+// fetchReferrers lists artifacts of artifactType referring to manifest dgst using the OCI 1.1 referrers API.
+// supported is false when registry doesn't implement the referrers API
+func fetchReferrers(ctx context.Context, opt imagetools.Opt, repository reference.Named, dgst digest.Digest, artifactType string) (index v1.Index, supported bool, err error) {
+	hosts, err := resolver.NewRegistryConfig(opt.RegistryConfig)(reference.Domain(repository))
+	if err != nil {
+		return index, false, err
+	}
+	authorizer := docker.NewDockerAuthorizer(docker.WithAuthCreds(func(host string) (string, string, error) {
+		if opt.Auth == nil {
+			return "", "", nil
+		}
+		if host == "registry-1.docker.io" {
+			host = "https://index.docker.io/v1/"
+		}
+		auth, err := opt.Auth.GetAuthConfig(host)
+		if err != nil {
+			return "", "", err
+		}
+		if auth.IdentityToken != "" {
+			return "", auth.IdentityToken, nil
+		}
+		return auth.Username, auth.Password, nil
+	}))
+
+	for _, host := range hosts {
+		if !host.Capabilities.Has(docker.HostCapabilityPull) {
+			continue
+		}
+		u := url.URL{
+			Scheme:   host.Scheme,
+			Host:     host.Host,
+			Path:     path.Join(host.Path, reference.Path(repository), "referrers", dgst.String()),
+			RawQuery: url.Values{"artifactType": []string{artifactType}}.Encode(),
+		}
+		var resp *http.Response
+		resp, err = getWithAuth(ctx, host, authorizer, u.String())
+		if err != nil {
+			continue // try next host
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return index, false, nil
+		case resp.StatusCode != http.StatusOK:
+			return index, false, fmt.Errorf("looking up signatures of %s@%s: unexpected status %s", repository.String(), dgst, resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&index)
+		return index, true, err
+	}
+	if err != nil {
+		return index, false, fmt.Errorf("looking up signatures of %s@%s: %w", repository.String(), dgst, err)
+	}
+	return index, false, nil
+}
+
+// getWithAuth sends a GET request to a registry, and authenticates as requested by registry
+func getWithAuth(ctx context.Context, host docker.RegistryHost, authorizer docker.Authorizer, u string) (*http.Response, error) {
+	client := host.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	for retry := false; ; retry = true {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", v1.MediaTypeImageIndex)
+		for k, v := range host.Header {
+			req.Header[k] = v
+		}
+		if err := authorizer.Authorize(ctx, req); err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized || retry {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		// registry requested authentication, retry with credentials
+		if err := authorizer.AddResponses(ctx, []*http.Response{resp}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func getManifest(ctx context.Context, resolver *imagetools.Resolver, repository reference.Named, desc v1.Descriptor) (v1.Manifest, error) {
+	var manifest v1.Manifest
+	content, err := getBlob(ctx, resolver, repository, desc)
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(content, &manifest)
+	return manifest, err
+}
+
+func getBlob(ctx context.Context, resolver *imagetools.Resolver, repository reference.Named, desc v1.Descriptor) ([]byte, error) {
+	content, err := resolver.GetDescriptor(ctx, repository.String(), desc)
+	if err != nil {
+		return nil, err
+	}
+	if digest.FromBytes(content) != desc.Digest {
+		return nil, fmt.Errorf("content of %s doesn't match its digest", desc.Digest)
+	}
+	return content, nil
+}
+
+// verifySignature checks a cosign simple signing payload targets manifest dgst in repository, and signature is
+// a base64 encoded signature of payload by one of keys
+func verifySignature(keys []crypto.PublicKey, payload []byte, signature string, repository reference.Named, dgst digest.Digest) error {
+	var p simpleSigningPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("unexpected signature payload: %w", err)
+	}
+	if p.Critical.Type != cosignSignatureType {
+		return fmt.Errorf("unexpected signature type %q", p.Critical.Type)
+	}
+	if p.Critical.Image.DockerManifestDigest != dgst.String() {
+		return fmt.Errorf("signature is for %s", p.Critical.Image.DockerManifestDigest)
+	}
+	signed, err := reference.ParseNormalizedNamed(p.Critical.Identity.DockerReference)
+	if err != nil || signed.Name() != repository.Name() {
+		return fmt.Errorf("signature is for repository %q", p.Critical.Identity.DockerReference)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not base64 encoded: %w", err)
+	}
+	hash := sha256.Sum256(payload)
+	for _, key := range keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, hash[:], sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], sig) == nil {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, payload, sig) {
+				return nil
+			}
+		}
+	}
+	return errors.New("not signed by any of the configured public keys")
+}
@@ -0,0 +1,280 @@
+/*
+   Copyright 2024 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/buildx/util/resolver"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+const signedManifestDigest = digest.Digest("sha256:8b5ac5cbe2c3a8a8a7e2ba1bdf0fb8e0e1a5d1c4f2f1f0e8e2a3c8e5d4f9b0a1")
+
+func signingPayload(repository string, dgst digest.Digest) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, repository, dgst))
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	repository, err := reference.ParseNormalizedNamed("example.com/org/project")
+	assert.NilError(t, err)
+
+	payload := signingPayload("example.com/org/project", signedManifestDigest)
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	assert.NilError(t, err)
+	signature := base64.StdEncoding.EncodeToString(sig)
+
+	err = verifySignature([]crypto.PublicKey{other, &key.PublicKey}, payload, signature, repository, signedManifestDigest)
+	assert.NilError(t, err)
+
+	err = verifySignature([]crypto.PublicKey{other}, payload, signature, repository, signedManifestDigest)
+	assert.Error(t, err, "not signed by any of the configured public keys")
+
+	err = verifySignature([]crypto.PublicKey{&key.PublicKey}, payload, signature, repository, digest.FromString("other"))
+	assert.ErrorContains(t, err, "signature is for sha256:8b5ac5cb")
+
+	otherRepository, err := reference.ParseNormalizedNamed("example.com/org/other")
+	assert.NilError(t, err)
+	err = verifySignature([]crypto.PublicKey{&key.PublicKey}, payload, signature, otherRepository, signedManifestDigest)
+	assert.Error(t, err, `signature is for repository "example.com/org/project"`)
+}
+
+func TestNewSignatureVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NilError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "cosign.pub")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600)
+	assert.NilError(t, err)
+
+	t.Setenv(OCI_REMOTE_PUBLIC_KEYS, "")
+	t.Setenv(OCI_REMOTE_SIGNATURE_POLICY, "")
+	v, err := newSignatureVerifier(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, v.policy, SignaturePolicyIgnore)
+
+	t.Setenv(OCI_REMOTE_SIGNATURE_POLICY, SignaturePolicyEnforce)
+	_, err = newSignatureVerifier(nil)
+	assert.ErrorContains(t, err, "requires public keys")
+
+	t.Setenv(OCI_REMOTE_PUBLIC_KEYS, keyFile)
+	t.Setenv(OCI_REMOTE_SIGNATURE_POLICY, "")
+	v, err = newSignatureVerifier(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, v.policy, SignaturePolicyEnforce)
+	assert.Equal(t, len(v.keys), 1)
+
+	t.Setenv(OCI_REMOTE_SIGNATURE_POLICY, "sometimes")
+	_, err = newSignatureVerifier(nil)
+	assert.ErrorContains(t, err, `invalid OCI signature policy "sometimes"`)
+}
+
+// fakeSignatureRegistry serves a cosign signature of manifest signedManifestDigest, as a referrer using the referrers
+// API, or using the referrers tag schema when referrersStatus is set. `.sig` tag lookup gets sigStatus
+type fakeSignatureRegistry struct {
+	*httptest.Server
+	referrersStatus int
+	sigStatus       int
+	requests        []string
+}
+
+func newFakeSignatureRegistry(t *testing.T, key *ecdsa.PrivateKey, referrersStatus int, sigStatus int) *fakeSignatureRegistry {
+	r := &fakeSignatureRegistry{referrersStatus: referrersStatus, sigStatus: sigStatus}
+	r.Server = httptest.NewUnstartedServer(nil)
+	repository := r.Listener.Addr().String() + "/org/project"
+
+	payload := signingPayload(repository, signedManifestDigest)
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	assert.NilError(t, err)
+	manifest, err := json.Marshal(v1.Manifest{
+		MediaType: v1.MediaTypeImageManifest,
+		Layers: []v1.Descriptor{{
+			MediaType:   "application/vnd.dev.cosign.simplesigning.v1+json",
+			Digest:      digest.FromBytes(payload),
+			Size:        int64(len(payload)),
+			Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		}},
+	})
+	assert.NilError(t, err)
+	index, err := json.Marshal(v1.Index{
+		MediaType: v1.MediaTypeImageIndex,
+		Manifests: []v1.Descriptor{{
+			MediaType:    v1.MediaTypeImageManifest,
+			ArtifactType: cosignSignatureArtifactType,
+			Digest:       digest.FromBytes(manifest),
+			Size:         int64(len(manifest)),
+		}},
+	})
+	assert.NilError(t, err)
+
+	r.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.requests = append(r.requests, req.URL.Path)
+		serve := func(mediaType string, content []byte) {
+			w.Header().Set("Content-Type", mediaType)
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(content).String())
+			if req.Method == http.MethodGet {
+				_, _ = w.Write(content)
+			}
+		}
+		tag := fmt.Sprintf("%s-%s", signedManifestDigest.Algorithm(), signedManifestDigest.Encoded())
+		switch req.URL.Path {
+		case "/v2/org/project/referrers/" + signedManifestDigest.String():
+			if r.referrersStatus != 0 {
+				w.WriteHeader(r.referrersStatus)
+				return
+			}
+			assert.Equal(t, req.URL.Query().Get("artifactType"), cosignSignatureArtifactType)
+			serve(v1.MediaTypeImageIndex, index)
+		case "/v2/org/project/manifests/" + tag, "/v2/org/project/manifests/" + digest.FromBytes(index).String():
+			serve(v1.MediaTypeImageIndex, index)
+		case "/v2/org/project/manifests/" + tag + ".sig":
+			w.WriteHeader(r.sigStatus)
+		case "/v2/org/project/manifests/" + digest.FromBytes(manifest).String():
+			serve(v1.MediaTypeImageManifest, manifest)
+		case "/v2/org/project/blobs/" + digest.FromBytes(payload).String():
+			serve("application/vnd.dev.cosign.simplesigning.v1+json", payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	r.Start()
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestFindSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name            string
+		keys            []crypto.PublicKey
+		referrersStatus int
+		sigStatus       int
+		expectedErr     string
+		tagSchema       bool
+	}{
+		{
+			name:      "referrers API",
+			keys:      []crypto.PublicKey{&key.PublicKey},
+			sigStatus: http.StatusNotFound,
+		},
+		{
+			name:            "referrers tag schema",
+			keys:            []crypto.PublicKey{&key.PublicKey},
+			referrersStatus: http.StatusNotFound,
+			sigStatus:       http.StatusNotFound,
+			tagSchema:       true,
+		},
+		{
+			name:        "not signed by trusted key",
+			keys:        []crypto.PublicKey{&other.PublicKey},
+			sigStatus:   http.StatusNotFound,
+			expectedErr: "not signed by any of the configured public keys",
+		},
+		{
+			name:            "referrers API failure",
+			keys:            []crypto.PublicKey{&key.PublicKey},
+			referrersStatus: http.StatusInternalServerError,
+			sigStatus:       http.StatusNotFound,
+			expectedErr:     "unexpected status 500 Internal Server Error",
+		},
+		{
+			name:        "signature tag lookup failure",
+			keys:        []crypto.PublicKey{&key.PublicKey},
+			sigStatus:   http.StatusInternalServerError,
+			expectedErr: "looking up signatures of",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newFakeSignatureRegistry(t, key, tt.referrersStatus, tt.sigStatus)
+			host := strings.TrimPrefix(registry.URL, "http://")
+			plainHTTP := true
+			opt := imagetools.Opt{
+				Auth:           &configfile.ConfigFile{},
+				RegistryConfig: map[string]resolver.RegistryConfig{host: {PlainHTTP: &plainHTTP}},
+			}
+			ref, err := reference.ParseNormalizedNamed(host + "/org/project")
+			assert.NilError(t, err)
+
+			v := signatureVerifier{policy: SignaturePolicyEnforce, keys: tt.keys}
+			err = v.verify(context.Background(), opt, ref, signedManifestDigest)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			tag := fmt.Sprintf("/v2/org/project/manifests/%s-%s", signedManifestDigest.Algorithm(), signedManifestDigest.Encoded())
+			assert.Equal(t, contains(registry.requests, tag), tt.tagSchema, "referrers tag schema is only used as a fallback")
+		})
+	}
+}
+
+func TestFindSignatureNotFound(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	registry := newFakeSignatureRegistry(t, key, http.StatusNotFound, http.StatusNotFound)
+	host := strings.TrimPrefix(registry.URL, "http://")
+	plainHTTP := true
+	opt := imagetools.Opt{
+		Auth:           &configfile.ConfigFile{},
+		RegistryConfig: map[string]resolver.RegistryConfig{host: {PlainHTTP: &plainHTTP}},
+	}
+	ref, err := reference.ParseNormalizedNamed(host + "/org/other")
+	assert.NilError(t, err)
+
+	v := signatureVerifier{policy: SignaturePolicyEnforce, keys: []crypto.PublicKey{&key.PublicKey}}
+	err = v.verify(context.Background(), opt, ref, signedManifestDigest)
+	assert.ErrorContains(t, err, "no signature found for "+host+"/org/other@")
+
+	v.policy = SignaturePolicyWarn
+	assert.NilError(t, v.verify(context.Background(), opt, ref, signedManifestDigest))
+}